@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/dev-shimada/dcstop/internal/ui"
+)
+
+// projectResult records the outcome of stopping a single project.
+type projectResult struct {
+	name string
+	err  error
+	// reclaimed is -1 if the disk space freed is unknown, see report.Project.
+	reclaimed int64
+}
+
+// runStopAll stops every devcontainer found on the Docker daemon by label,
+// without looking up any devcontainer.json.
+func runStopAll() error {
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	containerOps := docker.NewContainerOps(dockerClient)
+	composeOps := docker.NewComposeOps(dockerClient)
+	composeOps.SetParallel(parallelFlag)
+	composeOps.SetStopOptions(stopOptions())
+	composeOps.SetBackupDir(backupFlag)
+
+	containers, err := containerOps.ListDevcontainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// Compose projects that were not created by devcontainer are left alone
+	var projects []*docker.Project
+	for _, p := range docker.GroupProjects(containers) {
+		if p.IsDevcontainer() {
+			projects = append(projects, p)
+		}
+	}
+
+	rep := &report.Report{DryRun: dryRunFlag, Projects: make([]*report.Project, 0, len(projects))}
+
+	if len(projects) == 0 {
+		printf("No devcontainers found\n")
+		return writeReport(rep)
+	}
+
+	results := stopProjects(ctx, composeOps, projects, rep)
+
+	if err := writeReport(rep); err != nil {
+		return err
+	}
+	return runError(rep, printSummary(results))
+}
+
+// stopProjects stops, or with --down removes, each of the given projects in turn,
+// adding them to the report. A failure is recorded and the next project is tried.
+func stopProjects(ctx context.Context, composeOps *docker.ComposeOps, projects []*docker.Project, rep *report.Report) []projectResult {
+	results := make([]projectResult, 0, len(projects))
+	for _, p := range projects {
+		printf("==> %s\n", p.Name)
+
+		rp := report.NewProject(p)
+		var err error
+		if p.IsCompose() {
+			err = stopCompose(ctx, composeOps, p.ComposeProject, nil, nil, p.Containers, rp)
+		} else {
+			err = stopImage(ctx, composeOps, p.Containers, rp)
+		}
+		if err != nil {
+			rp.Error = err.Error()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+
+		rep.Projects = append(rep.Projects, rp)
+		results = append(results, projectResult{name: p.Name, err: err, reclaimed: rp.Reclaimed})
+	}
+	return results
+}
+
+// printSummary prints the per-project outcome and returns an error if any project failed.
+func printSummary(results []projectResult) error {
+	failed := 0
+	var reclaimed int64
+	// The total is only meaningful if the space of every project is known
+	reclaimedKnown := true
+	printf("\n")
+	printf("Summary:\n")
+	for _, r := range results {
+		if r.reclaimed < 0 {
+			reclaimedKnown = false
+		} else {
+			reclaimed += r.reclaimed
+		}
+		if r.err != nil {
+			failed++
+			printf("  FAILED  %s: %v\n", r.name, r.err)
+			continue
+		}
+		printf("  OK      %s\n", r.name)
+	}
+
+	if downFlag && !dryRunFlag && reclaimedKnown {
+		printf("Reclaimed %s in total\n", ui.FormatSize(reclaimed))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d project(s) failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/dev-shimada/dcstop/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show the disk space used by each devcontainer",
+	Long: `Show the disk space used by each devcontainer on the Docker daemon:
+the writable layers of its containers, the volumes of its compose project,
+and the images of its containers, including the vsc-* layers built by the
+Dev Containers tooling.
+
+Image sizes count only the layers not shared with other images. Images that
+containers outside a project use as well are shown as SHARED and left out of
+its TOTAL, and counted once in the shared total. The build cache cannot be
+attributed to a project and is shown as a total.`,
+	Args: cobra.NoArgs,
+	RunE: runDu,
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+}
+
+func runDu(cmd *cobra.Command, args []string) error {
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx := context.Background()
+
+	containers, err := docker.NewContainerOps(dockerClient).ListDevcontainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var projects []*docker.Project
+	for _, p := range docker.GroupProjects(containers) {
+		if p.IsDevcontainer() {
+			projects = append(projects, p)
+		}
+	}
+
+	usage, err := docker.NewComposeOps(dockerClient).DiskUsage(ctx, docker.DiskUsageOptions{
+		Containers: true,
+		Images:     true,
+		Volumes:    true,
+		BuildCache: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	rep := &report.Report{
+		Projects:     make([]*report.Project, 0, len(projects)),
+		BuildCache:   usage.BuildCacheSize,
+		SharedImages: usage.SharedImageSize(projects),
+	}
+	usages := make([]docker.ProjectUsage, len(projects))
+	for i, p := range projects {
+		usages[i] = usage.ProjectUsage(p)
+		rp := report.NewProject(p)
+		rp.Usage = report.NewUsage(usages[i])
+		rep.Projects = append(rep.Projects, rp)
+	}
+	if err := writeReport(rep); err != nil {
+		return err
+	}
+
+	if len(projects) == 0 {
+		printf("No devcontainers found\n")
+	} else {
+		w := tabwriter.NewWriter(humanOut, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "PROJECT\tCONTAINERS\tVOLUMES\tIMAGES\tSHARED\tTOTAL")
+		for i, p := range projects {
+			u := usages[i]
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", p.Name,
+				ui.FormatSize(u.Containers), ui.FormatSize(u.Volumes), ui.FormatSize(u.Images),
+				ui.FormatSize(u.SharedImages), ui.FormatSize(u.Total()))
+		}
+		_ = w.Flush()
+		printf("\n")
+		printf("Shared images (all projects): %s\n", ui.FormatSize(rep.SharedImages))
+	}
+	printf("Build cache (all projects): %s\n", ui.FormatSize(usage.BuildCacheSize))
+
+	return nil
+}
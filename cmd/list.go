@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls", "ps"},
+	Short:   "List devcontainers on the Docker daemon",
+	Long: `List every container on the Docker daemon that was created by devcontainer
+or Docker Compose, grouped by project.`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx := context.Background()
+
+	ops := docker.NewContainerOps(dockerClient)
+	containers, err := ops.ListDevcontainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	projects := docker.GroupProjects(containers)
+
+	rep := &report.Report{Projects: make([]*report.Project, 0, len(projects))}
+	for _, p := range projects {
+		rep.Projects = append(rep.Projects, report.NewProject(p))
+	}
+	if err := writeReport(rep); err != nil {
+		return err
+	}
+
+	if len(projects) == 0 {
+		printf("No devcontainers found\n")
+		return nil
+	}
+
+	for i, p := range projects {
+		if i > 0 {
+			printf("\n")
+		}
+		printProject(p)
+	}
+
+	return nil
+}
+
+// printProject prints a project header followed by a table of its containers.
+func printProject(p *docker.Project) {
+	projectType := "image"
+	if p.IsCompose() {
+		projectType = "compose"
+	}
+	printf("%s (%s)\n", p.Name, projectType)
+
+	if p.LocalFolder != "" {
+		printf("  Folder:          %s\n", p.LocalFolder)
+	}
+	if p.ConfigFile != "" {
+		printf("  Config:          %s\n", p.ConfigFile)
+	}
+	if p.ComposeProject != "" {
+		printf("  Compose project: %s\n", p.ComposeProject)
+	}
+
+	w := tabwriter.NewWriter(humanOut, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  CONTAINER ID\tNAME\tSTATE\tSTATUS")
+	for _, c := range p.Containers {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", c.ShortID(), c.Name(), c.State, c.Status)
+	}
+	_ = w.Flush()
+}
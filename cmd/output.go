@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dev-shimada/dcstop/internal/report"
+)
+
+var outputFlag string
+
+// humanOut receives human-readable output. It is discarded when a structured
+// output format is selected, so that stdout carries only the report.
+var humanOut io.Writer = os.Stdout
+
+// printf prints human-readable output.
+func printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(humanOut, format, a...)
+}
+
+// setupOutput validates --output and routes human-readable output accordingly.
+func setupOutput() error {
+	if err := report.ValidateFormat(outputFlag); err != nil {
+		return err
+	}
+	if outputFlag != report.FormatHuman {
+		humanOut = io.Discard
+	}
+	return nil
+}
+
+// writeReport writes the report to stdout when a structured output format is selected.
+func writeReport(r *report.Report) error {
+	if outputFlag == report.FormatHuman {
+		return nil
+	}
+	r.Summary = r.Tally()
+	return report.Write(os.Stdout, outputFlag, r)
+}
+
+// Exit codes.
+const (
+	// exitFailure means nothing could be done, or the command could not run at all.
+	exitFailure = 1
+	// exitPartialFailure means some actions succeeded and others failed.
+	exitPartialFailure = 2
+)
+
+// exitError carries the exit code for a failed run.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// runError attaches the exit code for err, telling partial from total failure by the report.
+func runError(r *report.Report, err error) error {
+	if err == nil {
+		return nil
+	}
+	if r.Tally().PartialFailure() {
+		return &exitError{code: exitPartialFailure, err: err}
+	}
+	return &exitError{code: exitFailure, err: err}
+}
+
+// exitCode returns the process exit code for an error returned by a command.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/ui"
+)
+
+// printPlan prints the resources a plan would act on.
+func printPlan(plan *docker.Plan) {
+	if plan.ProjectName != "" {
+		printf("Dry run: plan for compose project '%s' (no changes made)\n", plan.ProjectName)
+	} else {
+		printf("Dry run: plan (no changes made)\n")
+	}
+
+	if len(plan.Services) > 0 {
+		printf("  Only service(s): %s (networks and volumes are kept)\n", strings.Join(plan.Services, ", "))
+	}
+
+	if len(plan.Containers) == 0 && len(plan.Networks) == 0 && len(plan.Volumes) == 0 && len(plan.Images) == 0 {
+		printf("  Nothing to do\n")
+		return
+	}
+
+	if len(plan.Containers) > 0 {
+		// Containers with the same step number are handled concurrently
+		order := plan.StopOrder()
+		printf("  Stop containers:\n")
+		printContainerOrder(order)
+		if plan.RemoveContainers {
+			printf("  Remove containers:\n")
+			printContainerOrder(order)
+		}
+	}
+
+	if len(plan.Networks) > 0 {
+		printf("  Remove networks:\n")
+		for _, n := range plan.Networks {
+			printf("    - %s\n", n.Name)
+		}
+	}
+
+	if len(plan.Volumes) > 0 {
+		if plan.BackupDir != "" {
+			printf("  Back up volumes to %s:\n", plan.BackupDir)
+			for _, v := range plan.Volumes {
+				printf("    - %s\n", v.Name)
+			}
+		}
+		printf("  Remove volumes:\n")
+		for _, v := range plan.Volumes {
+			printf("    - %s\n", v.Name)
+		}
+	}
+
+	if len(plan.Images) > 0 {
+		printf("  Remove images:\n")
+		for _, img := range plan.Images {
+			printf("    - %s (%s)\n", img.Name(), ui.FormatSize(img.Size))
+		}
+	}
+}
+
+// printContainerOrder prints containers batch by batch, numbering each batch.
+func printContainerOrder(order [][]docker.ContainerInfo) {
+	for i, batch := range order {
+		for _, c := range batch {
+			printf("    %d. %s (%s)\n", i+1, c.Name(), c.ShortID())
+		}
+	}
+}
+
+// printResults prints the outcome of every action attempted when applying a plan.
+func printResults(results []docker.ActionResult) {
+	printf("Results:\n")
+	for _, r := range results {
+		if r.Err != nil {
+			printf("  FAILED  %s %s %s: %v\n", r.Action, r.Type, r.Name, r.Err)
+			continue
+		}
+		printf("  OK      %s %s %s\n", r.Action, r.Type, r.Name)
+	}
+}
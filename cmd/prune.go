@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/dev-shimada/dcstop/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Stop or remove devcontainers whose devcontainer.json no longer exists",
+	Long: `Find every devcontainer on the Docker daemon whose devcontainer.json or
+compose files, as recorded in the container labels, no longer exist on disk,
+e.g. because the repository was deleted or moved, and stop them.
+
+With --down the orphaned containers are removed together with their compose
+networks, and with --volumes also their volumes.
+
+Files are checked on the machine dcstop runs on, so orphans are only
+detected reliably for a local Docker daemon.`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().BoolVarP(&downFlag, "down", "d", false, "Remove the orphaned containers and compose networks")
+	pruneCmd.Flags().BoolVarP(&volumesFlag, "volumes", "v", false, "Also remove volumes (requires --down)")
+	pruneCmd.Flags().StringVar(&backupFlag, "backup-volumes", "", "Archive each volume to a tarball in this directory before removing it (requires --volumes)")
+	pruneCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Print what would be stopped or removed without changing anything")
+	pruneCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Do not prompt for confirmation")
+	pruneCmd.Flags().IntVar(&parallelFlag, "parallel", docker.DefaultParallel, "Number of containers to stop or remove concurrently")
+	pruneCmd.Flags().VarP(&timeoutFlag, "timeout", "t", timeoutUsage)
+	pruneCmd.Flags().StringVarP(&signalFlag, "signal", "s", "", "Signal to send to stop containers (default: per service or container)")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	if volumesFlag && !downFlag {
+		return fmt.Errorf("--volumes requires --down flag")
+	}
+	if backupFlag != "" && !volumesFlag {
+		return fmt.Errorf("--backup-volumes requires --volumes flag")
+	}
+	if parallelFlag < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if timeoutFlag.value != nil && *timeoutFlag.value < -1 {
+		return fmt.Errorf("--timeout must be -1 or more")
+	}
+
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	containerOps := docker.NewContainerOps(dockerClient)
+	composeOps := docker.NewComposeOps(dockerClient)
+	composeOps.SetParallel(parallelFlag)
+	composeOps.SetStopOptions(stopOptions())
+	composeOps.SetBackupDir(backupFlag)
+
+	containers, err := containerOps.ListDevcontainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// As with --all, compose projects that were not created by devcontainer are left alone
+	var orphans []*docker.Project
+	for _, p := range docker.GroupProjects(containers) {
+		if p.IsDevcontainer() && p.IsOrphaned() {
+			orphans = append(orphans, p)
+		}
+	}
+
+	rep := &report.Report{DryRun: dryRunFlag, Projects: make([]*report.Project, 0, len(orphans))}
+
+	if len(orphans) == 0 {
+		printf("No orphaned devcontainers found\n")
+		return writeReport(rep)
+	}
+
+	printf("Found %d orphaned devcontainer(s):\n", len(orphans))
+	for _, p := range orphans {
+		printf("\n")
+		printProject(p)
+		for _, f := range p.MissingFiles() {
+			printf("  Missing:         %s\n", f)
+		}
+	}
+	printf("\n")
+
+	if !dryRunFlag {
+		confirmed, err := confirmPrune(len(orphans))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			printf("Aborted\n")
+			return writeReport(rep)
+		}
+	}
+
+	results := stopProjects(ctx, composeOps, orphans, rep)
+	for i, p := range orphans {
+		rep.Projects[i].MissingFiles = p.MissingFiles()
+	}
+
+	if err := writeReport(rep); err != nil {
+		return err
+	}
+	return runError(rep, printSummary(results))
+}
+
+// confirmPrune asks the user to confirm stopping or removing the orphaned projects.
+// Without a terminal to prompt on, it refuses unless --yes was given.
+func confirmPrune(count int) (bool, error) {
+	if yesFlag {
+		return true, nil
+	}
+	if !ui.IsInteractive() {
+		return false, fmt.Errorf("refusing to prune without confirmation; use --yes to skip the prompt")
+	}
+
+	action := "Stop"
+	switch {
+	case downFlag && volumesFlag:
+		action = "Remove (including volumes)"
+	case downFlag:
+		action = "Remove"
+	}
+	return ui.Confirm(fmt.Sprintf("%s %d orphaned devcontainer(s)", action, count))
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var restoreNameFlag string
+
+var restoreVolumesCmd = &cobra.Command{
+	Use:   "restore-volumes ARCHIVE...",
+	Short: "Recreate volumes from archives made with --backup-volumes",
+	Long: `Recreate each volume backed up with --backup-volumes from its archive,
+with its compose labels, so that the compose project uses it again when it
+is recreated.
+
+Existing volumes are never overwritten; remove the volume first, or restore
+it under another name with --name.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRestoreVolumes,
+}
+
+func init() {
+	restoreVolumesCmd.Flags().StringVar(&restoreNameFlag, "name", "", "Restore the volume under this name (only with a single archive)")
+	rootCmd.AddCommand(restoreVolumesCmd)
+}
+
+func runRestoreVolumes(cmd *cobra.Command, args []string) error {
+	if restoreNameFlag != "" && len(args) > 1 {
+		return fmt.Errorf("--name can only be used with a single archive")
+	}
+
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx := context.Background()
+	ops := docker.NewComposeOps(dockerClient)
+
+	rep := &report.Report{Projects: make([]*report.Project, 0, len(args))}
+	var errs []error
+	for _, path := range args {
+		rp := &report.Project{Name: filepath.Base(path), Containers: []report.Container{}, Resources: []report.Resource{}}
+
+		volume, err := ops.RestoreVolume(ctx, path, restoreNameFlag)
+		if err != nil {
+			rp.Error = err.Error()
+			errs = append(errs, err)
+			printf("FAILED  %s: %v\n", path, err)
+		} else {
+			rp.Name = volume.Name
+			rp.ComposeProject = volume.Labels[docker.LabelComposeProject]
+			rp.Resources = report.Results([]docker.ActionResult{{
+				Type: docker.ResourceVolume, ID: volume.Name, Name: volume.Name, Action: docker.ActionRestore,
+			}})
+			printf("OK      %s -> volume %s\n", path, volume.Name)
+		}
+		rep.Projects = append(rep.Projects, rp)
+	}
+
+	if err := writeReport(rep); err != nil {
+		return err
+	}
+	return runError(rep, errors.Join(errs...))
+}
@@ -4,18 +4,35 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/dev-shimada/dcstop/internal/devcontainer"
 	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
 	"github.com/dev-shimada/dcstop/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	downFlag    bool
-	volumesFlag bool
-	contextFlag string
+	downFlag     bool
+	volumesFlag  bool
+	allFlag      bool
+	dryRunFlag   bool
+	yesFlag      bool
+	parallelFlag int
+	timeoutFlag  optionalInt
+	signalFlag   string
+	allServices  bool
+	rmiFlag      string
+	backupFlag   string
+	recursive    bool
+	maxDepth     int
+	ignoreFlag   []string
+	contextFlag  string
 )
 
 var rootCmd = &cobra.Command{
@@ -26,22 +43,46 @@ var rootCmd = &cobra.Command{
 It searches for devcontainer.json in the specified directory (or current directory)
 and stops the associated containers.
 
-For image-based devcontainers, it stops containers by the devcontainer labels.
-For compose-based devcontainers, it stops the compose project.`,
+For image-based and Dockerfile-based devcontainers, it stops containers by the
+devcontainer labels.
+For compose-based devcontainers, it stops the compose project.
+
+If several devcontainer.json files are found, any number of them can be
+selected and stopped at once. With --recursive, devcontainer.json files are
+searched for in every subdirectory too.
+
+With --all, every devcontainer on the Docker daemon is stopped, regardless
+of the directory.`,
 	Args: cobra.MaximumNArgs(1),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupOutput()
+	},
 	RunE: runStop,
 }
 
 func init() {
 	rootCmd.Flags().BoolVarP(&downFlag, "down", "d", false, "Remove containers after stopping (for compose, also removes networks)")
 	rootCmd.Flags().BoolVarP(&volumesFlag, "volumes", "v", false, "Also remove volumes (requires --down)")
-	rootCmd.Flags().StringVarP(&contextFlag, "context", "c", "", "Docker context to use (default: current context)")
+	rootCmd.Flags().StringVar(&backupFlag, "backup-volumes", "", "Archive each volume to a tarball in this directory before removing it (requires --volumes)")
+	rootCmd.Flags().StringVar(&rmiFlag, "rmi", "", `Also remove images used by the containers: "local" for locally built images only, or "all" (requires --down)`)
+	rootCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "Stop every devcontainer on the Docker daemon")
+	rootCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Search subdirectories for devcontainer.json too")
+	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 3, "Levels of subdirectories to search with --recursive, -1 for no limit")
+	rootCmd.Flags().StringSliceVar(&ignoreFlag, "ignore", nil, "Glob of directory names to skip with --recursive, in addition to node_modules, .git and vendor (can be repeated)")
+	rootCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Print what would be stopped or removed without changing anything")
+	rootCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Do not prompt for confirmation before removing volumes or images")
+	rootCmd.Flags().IntVar(&parallelFlag, "parallel", docker.DefaultParallel, "Number of containers to stop or remove concurrently")
+	rootCmd.Flags().VarP(&timeoutFlag, "timeout", "t", timeoutUsage)
+	rootCmd.Flags().StringVarP(&signalFlag, "signal", "s", "", "Signal to send to stop containers (default: per service or container)")
+	rootCmd.Flags().BoolVar(&allServices, "all-services", false, "Stop every service of a compose project, even with shutdownAction stopContainer")
+	rootCmd.PersistentFlags().StringVarP(&contextFlag, "context", "c", "", "Docker context to use (default: current context)")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", report.FormatHuman, "Output format: human, json or yaml")
 }
 
 // Execute runs the root command.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -50,7 +91,139 @@ func runStop(cmd *cobra.Command, args []string) error {
 	if volumesFlag && !downFlag {
 		return fmt.Errorf("--volumes requires --down flag")
 	}
+	if backupFlag != "" && !volumesFlag {
+		return fmt.Errorf("--backup-volumes requires --volumes flag")
+	}
+	switch docker.RemoveImages(rmiFlag) {
+	case "", docker.RemoveImagesLocal, docker.RemoveImagesAll:
+	default:
+		return fmt.Errorf("--rmi must be %q or %q", docker.RemoveImagesLocal, docker.RemoveImagesAll)
+	}
+	if rmiFlag != "" && !downFlag {
+		return fmt.Errorf("--rmi requires --down flag")
+	}
+	if allFlag && len(args) > 0 {
+		return fmt.Errorf("--all cannot be used with a directory argument")
+	}
+	if allFlag && recursive {
+		return fmt.Errorf("--all cannot be used with --recursive")
+	}
+	if maxDepth < -1 {
+		return fmt.Errorf("--max-depth must be -1 or more")
+	}
+	if parallelFlag < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if timeoutFlag.value != nil && *timeoutFlag.value < -1 {
+		return fmt.Errorf("--timeout must be -1 or more")
+	}
+
+	if allFlag {
+		return runStopAll()
+	}
+
+	configs, err := findConfigs(args)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		printf("No devcontainer.json found\n")
+		return writeReport(&report.Report{DryRun: dryRunFlag, Projects: []*report.Project{}})
+	}
+
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Select configs if multiple
+	selected, err := ui.SelectConfigs(configs, containerCounter(ctx, dockerClient))
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		printf("No devcontainers selected\n")
+		return writeReport(&report.Report{DryRun: dryRunFlag, Projects: []*report.Project{}})
+	}
+
+	rep := &report.Report{DryRun: dryRunFlag, Projects: make([]*report.Project, 0, len(selected))}
+
+	if len(selected) == 1 {
+		rp := newProjectReport(selected[0])
+		err = stopConfig(ctx, dockerClient, selected[0], rp)
+		rep.Projects = append(rep.Projects, rp)
+		if writeErr := writeReport(rep); writeErr != nil {
+			return writeErr
+		}
+		return runError(rep, err)
+	}
+
+	results := stopConfigs(ctx, dockerClient, selected, rep)
+
+	if err := writeReport(rep); err != nil {
+		return err
+	}
+	return runError(rep, printSummary(results))
+}
+
+// timeoutUsage is the help text of --timeout, for every command that stops containers.
+const timeoutUsage = "Seconds to wait for each container to stop before killing it, -1 to wait indefinitely (default: per service or container)"
+
+// interruptContext returns a context that is cancelled on Ctrl-C, so that no new
+// work is started; actions already running finish.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// stopOptions returns the stop options given by --timeout and --signal.
+// Options not given are left to the compose service or the container.
+func stopOptions() docker.ContainerStopOptions {
+	return docker.ContainerStopOptions{
+		Timeout: timeoutFlag.value,
+		Signal:  signalFlag,
+	}
+}
+
+// optionalInt is an int flag that tells whether it was given, so that 0 can be
+// told apart from not set.
+type optionalInt struct {
+	value *int
+}
 
+func (o *optionalInt) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	o.value = &v
+	return nil
+}
+
+func (o *optionalInt) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.Itoa(*o.value)
+}
+
+func (o *optionalInt) Type() string {
+	return "int"
+}
+
+// findConfigs finds and parses the devcontainer configs in the directory given in
+// args, or the current directory, and with --recursive in its subdirectories.
+// Configs that fail to parse are skipped with a warning.
+// It returns nil if there are none.
+func findConfigs(args []string) ([]*devcontainer.Config, error) {
 	// Determine target directory
 	targetDir := "."
 	if len(args) > 0 {
@@ -60,148 +233,442 @@ func runStop(cmd *cobra.Command, args []string) error {
 	// Convert to absolute path
 	absDir, err := filepath.Abs(targetDir)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
 	// Find devcontainer configs
-	configPaths, err := devcontainer.FindDevcontainerConfigs(absDir)
+	var candidates []devcontainer.Candidate
+	if recursive {
+		candidates, err = devcontainer.FindDevcontainerConfigsRecursive(absDir, devcontainer.WalkOptions{
+			MaxDepth: maxDepth,
+			Ignore:   append(slices.Clone(devcontainer.DefaultIgnore), ignoreFlag...),
+		})
+	} else {
+		candidates, err = devcontainer.FindDevcontainerConfigs(absDir)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to find devcontainer configs: %w", err)
+		return nil, fmt.Errorf("failed to find devcontainer configs: %w", err)
 	}
 
-	if len(configPaths) == 0 {
-		fmt.Println("No devcontainer.json found")
-		return nil
+	if len(candidates) == 0 {
+		return nil, nil
 	}
 
 	// Parse all configs
-	configs := make([]*devcontainer.Config, 0, len(configPaths))
-	for _, path := range configPaths {
-		cfg, err := devcontainer.ParseConfig(path)
+	configs := make([]*devcontainer.Config, 0, len(candidates))
+	for _, candidate := range candidates {
+		cfg, err := devcontainer.ParseConfig(candidate.Path)
 		if err != nil {
-			fmt.Printf("Warning: failed to parse %s: %v\n", path, err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to parse %s: %v\n", candidate.Path, err)
 			continue
 		}
+		for _, warning := range cfg.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", candidate.Path, warning)
+		}
 		configs = append(configs, cfg)
 	}
 
 	if len(configs) == 0 {
-		return fmt.Errorf("no valid devcontainer configs found")
+		return nil, fmt.Errorf("no valid devcontainer configs found")
 	}
+	return configs, nil
+}
 
-	// Select config if multiple
-	selectedConfig, err := ui.SelectConfig(configs)
-	if err != nil {
-		return err
+// stopConfig stops, or with --down removes, the containers of a devcontainer config,
+// recording the outcome in rp.
+func stopConfig(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config, rp *report.Project) error {
+	// Handle based on config type. Image and Dockerfile based devcontainers
+	// are both single containers labelled by the devcontainer tooling.
+	var err error
+	switch cfg.Kind() {
+	case devcontainer.KindCompose:
+		err = handleCompose(ctx, client, cfg, rp)
+	default:
+		err = handleImage(ctx, client, cfg, rp)
 	}
-
-	// Create Docker client
-	dockerClient, err := docker.NewClientWithContext(contextFlag)
 	if err != nil {
-		return fmt.Errorf("failed to create docker client: %w", err)
+		rp.Error = err.Error()
 	}
-	defer func() {
-		if closeErr := dockerClient.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+	return err
+}
+
+// containerCounter returns a ui.CountFunc that counts the containers of a config
+// the way they are found when stopping it.
+func containerCounter(ctx context.Context, client *docker.RealDockerClient) ui.CountFunc {
+	return func(cfg *devcontainer.Config) (ui.ContainerCounts, error) {
+		containers, err := configContainers(ctx, client, cfg)
+		if err != nil {
+			return ui.ContainerCounts{}, err
 		}
-	}()
 
-	ctx := context.Background()
+		var counts ui.ContainerCounts
+		for _, c := range containers {
+			if c.State == "running" {
+				counts.Running++
+			} else {
+				counts.Stopped++
+			}
+		}
+		return counts, nil
+	}
+}
+
+// configContainers finds the containers of a devcontainer config: those of its
+// compose project, or those labelled by the devcontainer tooling.
+func configContainers(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config) ([]docker.ContainerInfo, error) {
+	if cfg.Kind() == devcontainer.KindCompose {
+		_, containers, _, err := lookupComposeProject(ctx, docker.NewComposeOps(client), cfg)
+		return containers, err
+	}
 
-	// Handle based on config type
-	if selectedConfig.IsComposeBased() {
-		return handleCompose(ctx, dockerClient, selectedConfig)
+	match, err := docker.NewContainerOps(client).FindDevcontainers(ctx, cfg.ConfigPath, cfg.WorkspaceFolder())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find containers: %w", err)
 	}
+	return match.Containers, nil
+}
+
+// stopConfigs stops, or with --down removes, the containers of each config in turn,
+// adding them to the report. As with --all, a failure is recorded and the next
+// config is tried.
+func stopConfigs(ctx context.Context, client *docker.RealDockerClient, configs []*devcontainer.Config, rep *report.Report) []projectResult {
+	results := make([]projectResult, 0, len(configs))
+	for _, cfg := range configs {
+		rp := newProjectReport(cfg)
+		printf("==> %s (%s)\n", rp.Name, cfg.ConfigPath)
+
+		err := stopConfig(ctx, client, cfg, rp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 
-	return handleImage(ctx, dockerClient, selectedConfig)
+		rep.Projects = append(rep.Projects, rp)
+		results = append(results, projectResult{name: rp.Name, err: err, reclaimed: rp.Reclaimed})
+	}
+	return results
 }
 
-func handleImage(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config) error {
-	ops := docker.NewContainerOps(client)
+// newProjectReport starts the report for a devcontainer config.
+func newProjectReport(cfg *devcontainer.Config) *report.Project {
+	return &report.Project{
+		Name:       docker.DeriveProjectNameFromConfig(cfg),
+		Config:     report.NewConfig(cfg),
+		Containers: []report.Container{},
+		Resources:  []report.Resource{},
+	}
+}
 
-	// Find containers by config path
-	containers, err := ops.FindDevcontainersByConfigPath(ctx, cfg.ConfigPath)
+func handleImage(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config, rp *report.Project) error {
+	// Find containers by config path, falling back to the workspace folder
+	match, err := docker.NewContainerOps(client).FindDevcontainers(ctx, cfg.ConfigPath, cfg.WorkspaceFolder())
 	if err != nil {
 		return fmt.Errorf("failed to find containers: %w", err)
 	}
 
-	if len(containers) == 0 {
-		fmt.Println("No running containers found for this devcontainer")
+	if len(match.Containers) == 0 {
+		printf("No running containers found for this devcontainer\n")
 		return nil
 	}
 
-	fmt.Printf("Found %d container(s) to stop\n", len(containers))
+	printf("Matched by %s\n", match.Strategy)
+	rp.Match = string(match.Strategy)
+
+	// Stopping goes through ComposeOps, which can also remove images
+	ops := docker.NewComposeOps(client)
+	ops.SetParallel(parallelFlag)
+	ops.SetStopOptions(stopOptions())
+	ops.SetBackupDir(backupFlag)
+	return stopImage(ctx, ops, match.Containers, rp)
+}
+
+// stopImage stops, and with --down removes, the containers of an image-based devcontainer.
+func stopImage(ctx context.Context, ops *docker.ComposeOps, containers []docker.ContainerInfo, rp *report.Project) error {
+	rp.Containers = report.Containers(containers)
+
+	printf("Found %d container(s) to stop\n", len(containers))
 	for _, c := range containers {
 		name := ""
 		if len(c.Names) > 0 {
 			name = c.Names[0]
 		}
-		fmt.Printf("  - %s (%s)\n", name, c.ID[:12])
+		printf("  - %s (%s)\n", name, c.ShortID())
 	}
 
-	// Stop containers
-	if err := ops.StopContainers(ctx, containers); err != nil {
+	plan := &docker.Plan{
+		Containers:       containers,
+		RemoveContainers: downFlag,
+	}
+	if err := planImages(ctx, ops, plan); err != nil {
+		return err
+	}
+
+	if dryRunFlag {
+		rp.Resources = report.Planned(plan)
+		printPlan(plan)
+		return nil
+	}
+
+	confirmed, err := confirmRemoval(ctx, ops, plan)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		printf("Aborted\n")
+		return nil
+	}
+
+	usage := usageBeforeRemoval(ctx, ops, plan)
+	results, err := ops.Apply(ctx, plan)
+	rp.Resources = report.Results(results)
+	if err != nil {
+		printResults(results)
+		printReclaimed(usage, plan, results, rp)
 		return err
 	}
 
 	if downFlag {
-		// Remove containers
-		if err := ops.RemoveContainers(ctx, containers); err != nil {
-			return err
-		}
-		fmt.Println("Containers stopped and removed successfully")
+		printf("Containers stopped and removed successfully\n")
 	} else {
-		fmt.Println("Containers stopped successfully")
+		printf("Containers stopped successfully\n")
 	}
+	printReclaimed(usage, plan, results, rp)
 	return nil
 }
 
-func handleCompose(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config) error {
+func handleCompose(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config, rp *report.Project) error {
 	ops := docker.NewComposeOps(client)
+	ops.SetParallel(parallelFlag)
+	ops.SetStopOptions(stopOptions())
+	ops.SetBackupDir(backupFlag)
+
+	projectName, containers, err := findComposeProject(ctx, ops, cfg, rp)
+	if err != nil {
+		return err
+	}
 
+	// With shutdownAction stopContainer, the editor only stops the primary service
+	var services []string
+	if !allServices {
+		services = cfg.StopServices(downFlag)
+	}
+	switch {
+	case len(services) > 0:
+		printf("shutdownAction is %s: only service '%s' is stopped (use --all-services to stop every service)\n",
+			devcontainer.ShutdownStopContainer, cfg.Service)
+	case downFlag && !allServices && cfg.StopServices(false) != nil:
+		printf("shutdownAction is %s, but --down removes every service of the project\n", devcontainer.ShutdownStopContainer)
+	}
+
+	return stopCompose(ctx, ops, projectName, cfg.GetComposeFiles(), services, containers, rp)
+}
+
+// findComposeProject finds the compose project of a compose-based devcontainer and its containers.
+func findComposeProject(ctx context.Context, ops *docker.ComposeOps, cfg *devcontainer.Config, rp *report.Project) (string, []docker.ContainerInfo, error) {
+	projectName, containers, byConfigFiles, err := lookupComposeProject(ctx, ops, cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	if byConfigFiles {
+		printf("Matched compose project '%s' by compose file labels\n", projectName)
+		rp.Match = docker.LabelComposeConfigFiles
+	}
+
+	rp.ComposeProject = projectName
+	return projectName, containers, nil
+}
+
+// lookupComposeProject finds the compose project of a compose-based devcontainer and
+// its containers, and tells whether it was matched by the compose file labels.
+func lookupComposeProject(ctx context.Context, ops *docker.ComposeOps, cfg *devcontainer.Config) (string, []docker.ContainerInfo, bool, error) {
 	// Derive project name from devcontainer config
 	projectName := docker.DeriveProjectNameFromConfig(cfg)
 
 	// Find containers
 	containers, err := ops.FindComposeContainers(ctx, projectName)
 	if err != nil {
-		return fmt.Errorf("failed to find compose containers: %w", err)
+		return "", nil, false, fmt.Errorf("failed to find compose containers: %w", err)
+	}
+	if len(containers) > 0 {
+		return projectName, containers, false, nil
+	}
+
+	// Fall back to the compose files recorded on the containers, in case the
+	// project was started under a name dcstop cannot derive
+	containers, err = ops.FindComposeContainersByConfigFiles(ctx, cfg.GetComposeFiles())
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to find compose containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return projectName, nil, false, nil
+	}
+
+	projectName = containers[0].Labels[docker.LabelComposeProject]
+	containers, err = ops.FindComposeContainers(ctx, projectName)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to find compose containers: %w", err)
 	}
+	return projectName, containers, true, nil
+}
+
+// stopCompose stops, and with --down tears down, a compose project.
+// The service dependencies are read from composeFiles, or from the compose files
+// recorded on the containers when none are given. If services is not empty, only
+// those services are stopped and the project's networks and volumes are kept.
+func stopCompose(ctx context.Context, ops *docker.ComposeOps, projectName string, composeFiles, services []string, containers []docker.ContainerInfo, rp *report.Project) error {
+	rp.ComposeProject = projectName
+	rp.Containers = report.Containers(containers)
 
 	if len(containers) == 0 {
 		if !downFlag {
-			fmt.Printf("No containers found for compose project '%s'\n", projectName)
+			printf("No containers found for compose project '%s'\n", projectName)
 			return nil
 		}
-		fmt.Printf("No containers found for compose project '%s', cleaning up resources...\n", projectName)
+		printf("No containers found for compose project '%s', cleaning up resources...\n", projectName)
 	} else {
-		fmt.Printf("Found %d container(s) in compose project '%s'\n", len(containers), projectName)
+		printf("Found %d container(s) in compose project '%s'\n", len(containers), projectName)
 		for _, c := range containers {
 			name := ""
 			if len(c.Names) > 0 {
 				name = c.Names[0]
 			}
-			fmt.Printf("  - %s (%s)\n", name, c.ID[:12])
+			printf("  - %s (%s)\n", name, c.ShortID())
 		}
 	}
 
+	// Collect containers, networks, and optionally volumes
+	var plan *docker.Plan
+	var err error
 	if downFlag {
-		// Stop and remove containers, networks, and optionally volumes
-		if err := ops.DownComposeProject(ctx, projectName, volumesFlag); err != nil {
-			return err
+		plan, err = ops.PlanDown(ctx, projectName, volumesFlag)
+	} else {
+		plan, err = ops.PlanStop(ctx, projectName)
+	}
+	if err != nil {
+		return err
+	}
+	if len(services) > 0 {
+		plan.OnlyServices(services)
+		rp.Services = services
+	}
+	if err := planImages(ctx, ops, plan); err != nil {
+		return err
+	}
+
+	// Stop services in reverse dependency order, with their stop_grace_period and
+	// stop_signal; without the compose files the containers are stopped all at once
+	if err := plan.LoadServices(composeFiles); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if dryRunFlag {
+		rp.Resources = report.Planned(plan)
+		printPlan(plan)
+		return nil
+	}
+
+	confirmed, err := confirmRemoval(ctx, ops, plan)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		printf("Aborted\n")
+		return nil
+	}
+
+	usage := usageBeforeRemoval(ctx, ops, plan)
+	results, err := ops.Apply(ctx, plan)
+	rp.Resources = report.Results(results)
+	if err != nil {
+		printResults(results)
+		printReclaimed(usage, plan, results, rp)
+		return err
+	}
+
+	// Report what the plan removed, which may be less than the flags asked for
+	switch {
+	case plan.RemoveContainers && len(plan.Volumes) > 0:
+		printf("Compose project stopped and removed (including volumes) successfully\n")
+	case plan.RemoveContainers:
+		printf("Compose project stopped and removed successfully\n")
+	default:
+		printf("Compose project stopped successfully\n")
+	}
+	printReclaimed(usage, plan, results, rp)
+
+	return nil
+}
+
+// planImages adds the images to remove with --rmi to the plan.
+func planImages(ctx context.Context, ops *docker.ComposeOps, plan *docker.Plan) error {
+	if rmiFlag == "" {
+		return nil
+	}
+	return ops.PlanImages(ctx, plan, docker.RemoveImages(rmiFlag))
+}
+
+// usageBeforeRemoval returns the disk usage of the resources the plan removes,
+// taken before they are removed, or nil if the plan removes nothing.
+// Sizes are only computed for the object types the plan removes, as that can be slow.
+func usageBeforeRemoval(ctx context.Context, ops *docker.ComposeOps, plan *docker.Plan) *docker.DiskUsage {
+	if !plan.ReclaimsSpace() {
+		return nil
+	}
+
+	usage, err := ops.DiskUsage(ctx, docker.DiskUsageOptions{
+		Containers: plan.RemoveContainers,
+		Volumes:    len(plan.Volumes) > 0,
+		Images:     len(plan.Images) > 0,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return usage
+}
+
+// printReclaimed records and prints the disk space freed by the removals in results.
+// If the plan removed resources but the disk usage could not be read, the space is
+// recorded as unknown.
+func printReclaimed(usage *docker.DiskUsage, plan *docker.Plan, results []docker.ActionResult, rp *report.Project) {
+	if usage == nil {
+		if plan.ReclaimsSpace() {
+			rp.Reclaimed = -1
+		}
+		return
+	}
+	rp.Reclaimed = usage.Reclaimed(results)
+	printf("Reclaimed %s\n", ui.FormatSize(rp.Reclaimed))
+}
+
+// confirmRemoval lists the volumes and images the plan is about to delete and asks
+// the user to confirm. Without a terminal to prompt on, it refuses unless --yes was given.
+func confirmRemoval(ctx context.Context, ops *docker.ComposeOps, plan *docker.Plan) (bool, error) {
+	if yesFlag || (len(plan.Volumes) == 0 && len(plan.Images) == 0) {
+		return true, nil
+	}
+	if !ui.IsInteractive() {
+		return false, fmt.Errorf("refusing to remove volumes or images without confirmation; use --yes to skip the prompt")
+	}
+
+	// The prompt is shown on stderr, so list the volumes and images there too
+	var what []string
+	if len(plan.Volumes) > 0 {
+		if err := ops.AddVolumeSizes(ctx, plan.Volumes); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 		}
-		if volumesFlag {
-			fmt.Println("Compose project stopped and removed (including volumes) successfully")
-		} else {
-			fmt.Println("Compose project stopped and removed successfully")
+		fmt.Fprintln(os.Stderr, "The following volumes will be permanently deleted:")
+		for _, v := range plan.Volumes {
+			fmt.Fprintf(os.Stderr, "  - %s (%s)\n", v.Name, ui.FormatSize(v.Size))
 		}
-	} else {
-		// Just stop containers
-		if err := ops.StopComposeProject(ctx, projectName); err != nil {
-			return err
+		what = append(what, fmt.Sprintf("%d volume(s)", len(plan.Volumes)))
+	}
+	if len(plan.Images) > 0 {
+		fmt.Fprintln(os.Stderr, "The following images will be deleted:")
+		for _, img := range plan.Images {
+			fmt.Fprintf(os.Stderr, "  - %s (%s)\n", img.Name(), ui.FormatSize(img.Size))
 		}
-		fmt.Println("Compose project stopped successfully")
+		what = append(what, fmt.Sprintf("%d image(s)", len(plan.Images)))
 	}
 
-	return nil
+	return ui.Confirm("Delete " + strings.Join(what, " and "))
 }
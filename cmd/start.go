@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dev-shimada/dcstop/internal/devcontainer"
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/dev-shimada/dcstop/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start [directory]",
+	Short: "Start the stopped containers of a devcontainer",
+	Long: `Start the containers of the devcontainer in the specified directory
+(or current directory) that were stopped, e.g. by dcstop.
+
+Compose services are started in dependency order. Containers are never
+created: services whose containers were removed are reported, and have to
+be recreated with the Dev Containers tooling.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStart(args, false)
+	},
+}
+
+var restartCmd = &cobra.Command{
+	Use:   "restart [directory]",
+	Short: "Restart the containers of a devcontainer",
+	Long: `Stop the containers of the devcontainer in the specified directory
+(or current directory), then start them again.
+
+Compose services are stopped in reverse dependency order and started in
+dependency order. Services whose containers were removed are reported,
+not recreated.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStart(args, true)
+	},
+}
+
+func init() {
+	startCmd.Flags().IntVar(&parallelFlag, "parallel", docker.DefaultParallel, "Number of containers to start concurrently")
+	restartCmd.Flags().IntVar(&parallelFlag, "parallel", docker.DefaultParallel, "Number of containers to stop or start concurrently")
+	restartCmd.Flags().VarP(&timeoutFlag, "timeout", "t", timeoutUsage)
+	restartCmd.Flags().StringVarP(&signalFlag, "signal", "s", "", "Signal to send to stop containers (default: per service or container)")
+	rootCmd.AddCommand(startCmd, restartCmd)
+}
+
+// runStart starts, or with restart stops and then starts, the containers of a devcontainer.
+func runStart(args []string, restart bool) error {
+	if parallelFlag < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if timeoutFlag.value != nil && *timeoutFlag.value < -1 {
+		return fmt.Errorf("--timeout must be -1 or more")
+	}
+
+	configs, err := findConfigs(args)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		printf("No devcontainer.json found\n")
+		return writeReport(&report.Report{Projects: []*report.Project{}})
+	}
+
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Select config if multiple
+	selectedConfig, err := ui.SelectConfig(configs, containerCounter(ctx, dockerClient))
+	if err != nil {
+		return err
+	}
+
+	rp := newProjectReport(selectedConfig)
+	err = startContainers(ctx, dockerClient, selectedConfig, restart, rp)
+	if err != nil {
+		rp.Error = err.Error()
+	}
+
+	rep := &report.Report{Projects: []*report.Project{rp}}
+	if writeErr := writeReport(rep); writeErr != nil {
+		return writeErr
+	}
+	return runError(rep, err)
+}
+
+// startContainers finds the containers of a devcontainer and starts or restarts them.
+func startContainers(ctx context.Context, client *docker.RealDockerClient, cfg *devcontainer.Config, restart bool, rp *report.Project) error {
+	ops := docker.NewContainerOps(client)
+	ops.SetParallel(parallelFlag)
+	ops.SetStopOptions(stopOptions())
+
+	plan := &docker.Plan{}
+	if cfg.Kind() == devcontainer.KindCompose {
+		projectName, containers, err := findComposeProject(ctx, docker.NewComposeOps(client), cfg, rp)
+		if err != nil {
+			return err
+		}
+		plan.ProjectName = projectName
+		plan.Containers = containers
+
+		// Start services in dependency order; without the compose files
+		// the containers are started all at once
+		if err := plan.LoadServices(cfg.GetComposeFiles()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	} else {
+		match, err := ops.FindDevcontainers(ctx, cfg.ConfigPath, cfg.WorkspaceFolder())
+		if err != nil {
+			return fmt.Errorf("failed to find containers: %w", err)
+		}
+		rp.Match = string(match.Strategy)
+		plan.Containers = match.Containers
+	}
+	rp.Containers = report.Containers(plan.Containers)
+
+	if len(plan.Containers) == 0 {
+		return fmt.Errorf("no containers found for this devcontainer; if they were removed, recreate them with the Dev Containers tooling")
+	}
+
+	// Services outside runServices are not started by the Dev Containers tooling either
+	missing := plan.MissingServices()
+	if len(cfg.RunServices) > 0 {
+		missing = slices.DeleteFunc(missing, func(service string) bool {
+			return !slices.Contains(cfg.RunServices, service) && service != cfg.Service
+		})
+	}
+	if len(missing) > 0 {
+		rp.MissingServices = missing
+		printf("No container for service(s) %s; recreate them with the Dev Containers tooling\n", strings.Join(missing, ", "))
+	}
+
+	var results []docker.ActionResult
+	var err error
+	if restart {
+		printf("Restarting %d container(s)\n", len(plan.Containers))
+		results, err = ops.Restart(ctx, plan)
+	} else {
+		printf("Starting %d container(s)\n", len(plan.Containers))
+		results, err = ops.Start(ctx, plan)
+	}
+	rp.Resources = report.Results(results)
+	if err != nil {
+		printResults(results)
+		return err
+	}
+
+	if restart {
+		printf("Containers restarted successfully\n")
+	} else {
+		printf("Containers started successfully\n")
+	}
+	return nil
+}
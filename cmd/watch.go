@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/dev-shimada/dcstop/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	idleFlag         time.Duration
+	cpuThresholdFlag float64
+	intervalFlag     time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stop devcontainers that have been idle for a while",
+	Long: `Watch the devcontainers on the Docker daemon and stop those that have been
+idle for longer than --idle, e.g. because the editor was closed or switched
+to "Reopen Folder Locally" without stopping them.
+
+A container is idle while its CPU usage is below --cpu-threshold and it has
+no exec sessions (such as the VS Code server) or attached clients.
+A devcontainer is stopped once all of its running containers are idle;
+compose projects are stopped in reverse dependency order.
+
+Every decision is logged. Stop watching with Ctrl-C.`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&idleFlag, "idle", docker.DefaultIdleTimeout, "How long a devcontainer has to be idle before it is stopped")
+	watchCmd.Flags().Float64Var(&cpuThresholdFlag, "cpu-threshold", docker.DefaultCPUThreshold, "CPU usage, in percent of one CPU, below which a container is idle")
+	watchCmd.Flags().DurationVar(&intervalFlag, "interval", docker.DefaultInterval, "How often to sample the containers")
+	watchCmd.Flags().IntVar(&parallelFlag, "parallel", docker.DefaultParallel, "Number of containers to sample or stop concurrently")
+	watchCmd.Flags().VarP(&timeoutFlag, "timeout", "t", timeoutUsage)
+	watchCmd.Flags().StringVarP(&signalFlag, "signal", "s", "", "Signal to send to stop containers (default: per service or container)")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if outputFlag != report.FormatHuman {
+		return fmt.Errorf("watch only supports --output %s", report.FormatHuman)
+	}
+	if idleFlag <= 0 {
+		return fmt.Errorf("--idle must be positive")
+	}
+	if cpuThresholdFlag <= 0 {
+		return fmt.Errorf("--cpu-threshold must be positive")
+	}
+	if intervalFlag <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if parallelFlag < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if timeoutFlag.value != nil && *timeoutFlag.value < -1 {
+		return fmt.Errorf("--timeout must be -1 or more")
+	}
+
+	// Create Docker client
+	dockerClient, err := docker.NewClientWithContext(contextFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close docker client: %v\n", closeErr)
+		}
+	}()
+
+	// Run until interrupted, or terminated e.g. by a service manager
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ops := docker.NewComposeOps(dockerClient)
+	ops.SetParallel(parallelFlag)
+	ops.SetStopOptions(stopOptions())
+
+	watcher := docker.NewWatcher(dockerClient, ops, docker.WatchOptions{
+		IdleTimeout:  idleFlag,
+		CPUThreshold: cpuThresholdFlag,
+		Interval:     intervalFlag,
+	})
+	watcher.SetLogger(func(format string, args ...any) {
+		printf("%s %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+	})
+
+	printf("%s Watching devcontainers: stopping after %s idle (CPU below %.1f%%, sampled every %s)\n",
+		time.Now().Format(time.RFC3339), idleFlag, cpuThresholdFlag, intervalFlag)
+	return watcher.Run(ctx)
+}
@@ -6,26 +6,64 @@ import (
 	"path/filepath"
 )
 
-// FindDevcontainerConfigs searches for devcontainer.json files in the given directory.
-// It looks for:
+// Layout describes where a devcontainer.json is located in a workspace.
+type Layout string
+
+const (
+	// LayoutDevcontainerDir is .devcontainer/devcontainer.json.
+	LayoutDevcontainerDir Layout = "devcontainer-dir"
+	// LayoutDevcontainerSubdir is .devcontainer/<folder>/devcontainer.json.
+	LayoutDevcontainerSubdir Layout = "devcontainer-subdir"
+	// LayoutWorkspaceRoot is .devcontainer.json in the workspace root.
+	LayoutWorkspaceRoot Layout = "workspace-root"
+)
+
+// Candidate is a devcontainer config file found in a workspace,
+// tagged with the layout it was found in.
+type Candidate struct {
+	Path   string
+	Layout Layout
+}
+
+// DetectLayout determines the layout of a devcontainer config from its path.
+func DetectLayout(configPath string) Layout {
+	if filepath.Base(configPath) == ".devcontainer.json" {
+		return LayoutWorkspaceRoot
+	}
+	if filepath.Base(filepath.Dir(configPath)) == ".devcontainer" {
+		return LayoutDevcontainerDir
+	}
+	return LayoutDevcontainerSubdir
+}
+
+// FindDevcontainerConfigs searches for devcontainer config files in the given directory.
+// It looks for every location allowed by the Dev Containers spec:
 // - .devcontainer/devcontainer.json
+// - .devcontainer.json
 // - .devcontainer/*/devcontainer.json (subdirectories)
-func FindDevcontainerConfigs(dir string) ([]string, error) {
+func FindDevcontainerConfigs(dir string) ([]Candidate, error) {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", dir)
 	}
 
-	var configs []string
+	var configs []Candidate
 
 	devcontainerDir := filepath.Join(dir, ".devcontainer")
-	if _, err := os.Stat(devcontainerDir); os.IsNotExist(err) {
-		return configs, nil
-	}
 
 	// Check .devcontainer/devcontainer.json
 	mainConfig := filepath.Join(devcontainerDir, "devcontainer.json")
 	if _, err := os.Stat(mainConfig); err == nil {
-		configs = append(configs, mainConfig)
+		configs = append(configs, Candidate{Path: mainConfig, Layout: LayoutDevcontainerDir})
+	}
+
+	// Check .devcontainer.json
+	rootConfig := filepath.Join(dir, ".devcontainer.json")
+	if info, err := os.Stat(rootConfig); err == nil && !info.IsDir() {
+		configs = append(configs, Candidate{Path: rootConfig, Layout: LayoutWorkspaceRoot})
+	}
+
+	if _, err := os.Stat(devcontainerDir); os.IsNotExist(err) {
+		return configs, nil
 	}
 
 	// Check .devcontainer/*/devcontainer.json
@@ -41,7 +79,7 @@ func FindDevcontainerConfigs(dir string) ([]string, error) {
 
 		subConfig := filepath.Join(devcontainerDir, entry.Name(), "devcontainer.json")
 		if _, err := os.Stat(subConfig); err == nil {
-			configs = append(configs, subConfig)
+			configs = append(configs, Candidate{Path: subConfig, Layout: LayoutDevcontainerSubdir})
 		}
 	}
 
@@ -21,7 +21,8 @@ func TestFindDevcontainerConfigs(t *testing.T) {
 		configs, err := FindDevcontainerConfigs(tmpDir)
 		require.NoError(t, err)
 		assert.Len(t, configs, 1)
-		assert.Equal(t, configPath, configs[0])
+		assert.Equal(t, configPath, configs[0].Path)
+		assert.Equal(t, LayoutDevcontainerDir, configs[0].Layout)
 	})
 
 	t.Run("finds multiple devcontainer.json in subdirectories", func(t *testing.T) {
@@ -48,9 +49,37 @@ func TestFindDevcontainerConfigs(t *testing.T) {
 		configs, err := FindDevcontainerConfigs(tmpDir)
 		require.NoError(t, err)
 		assert.Len(t, configs, 3)
-		assert.Contains(t, configs, config1)
-		assert.Contains(t, configs, config2)
-		assert.Contains(t, configs, config3)
+		assert.Contains(t, configs, Candidate{Path: config1, Layout: LayoutDevcontainerDir})
+		assert.Contains(t, configs, Candidate{Path: config2, Layout: LayoutDevcontainerSubdir})
+		assert.Contains(t, configs, Candidate{Path: config3, Layout: LayoutDevcontainerSubdir})
+	})
+
+	t.Run("finds .devcontainer.json in workspace root", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".devcontainer.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(`{"image": "golang:1.21"}`), 0644))
+
+		configs, err := FindDevcontainerConfigs(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{{Path: configPath, Layout: LayoutWorkspaceRoot}}, configs)
+	})
+
+	t.Run("finds root and .devcontainer configs together", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		rootConfig := filepath.Join(tmpDir, ".devcontainer.json")
+		require.NoError(t, os.WriteFile(rootConfig, []byte(`{"image": "golang:1.21"}`), 0644))
+
+		devcontainerDir := filepath.Join(tmpDir, ".devcontainer")
+		require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
+		mainConfig := filepath.Join(devcontainerDir, "devcontainer.json")
+		require.NoError(t, os.WriteFile(mainConfig, []byte(`{"image": "node:18"}`), 0644))
+
+		configs, err := FindDevcontainerConfigs(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{
+			{Path: mainConfig, Layout: LayoutDevcontainerDir},
+			{Path: rootConfig, Layout: LayoutWorkspaceRoot},
+		}, configs)
 	})
 
 	t.Run("returns empty slice when no devcontainer found", func(t *testing.T) {
@@ -67,3 +96,9 @@ func TestFindDevcontainerConfigs(t *testing.T) {
 		assert.Nil(t, configs)
 	})
 }
+
+func TestDetectLayout(t *testing.T) {
+	assert.Equal(t, LayoutDevcontainerDir, DetectLayout("/foo/bar/.devcontainer/devcontainer.json"))
+	assert.Equal(t, LayoutDevcontainerSubdir, DetectLayout("/foo/bar/.devcontainer/app1/devcontainer.json"))
+	assert.Equal(t, LayoutWorkspaceRoot, DetectLayout("/foo/bar/.devcontainer.json"))
+}
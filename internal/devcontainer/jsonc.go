@@ -0,0 +1,131 @@
+package devcontainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SyntaxError describes a syntax error in a JSONC document.
+// Line and Column are 1-based; Column counts bytes.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// newSyntaxError creates a SyntaxError for the given byte offset in data.
+func newSyntaxError(data []byte, offset int, msg string) *SyntaxError {
+	offset = max(0, min(offset, len(data)))
+	line := 1 + bytes.Count(data[:offset], []byte{'\n'})
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return &SyntaxError{Line: line, Column: column, Msg: msg}
+}
+
+// stripJSONC converts JSONC content to plain JSON.
+// Comments and trailing commas are replaced with spaces rather than removed,
+// so byte offsets in the result still point at the same place in the original.
+func stripJSONC(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	// Offset of the last comma seen outside a string, or -1
+	pendingComma := -1
+	// Whether the last token seen outside a comment ends a value; only a comma
+	// after a value can be a trailing comma
+	afterValue := false
+
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			pendingComma = -1
+			afterValue = true
+			start := i
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(out) {
+				return nil, newSyntaxError(data, start, "unterminated string")
+			}
+
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			start := i
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, newSyntaxError(data, start, "unterminated block comment")
+			}
+			end += i + 4
+			for ; i < end; i++ {
+				// Keep newlines so line numbers are preserved
+				if out[i] != '\n' && out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+			i--
+
+		case c == ',':
+			// A comma with no value before it, as in [,] or [1,,], is left for json to reject
+			pendingComma = -1
+			if afterValue {
+				pendingComma = i
+			}
+			afterValue = false
+
+		case c == '}' || c == ']':
+			if pendingComma >= 0 {
+				out[pendingComma] = ' '
+			}
+			pendingComma = -1
+			afterValue = true
+
+		case c == '{' || c == '[' || c == ':':
+			pendingComma = -1
+			afterValue = false
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			// Whitespace does not affect trailing comma detection
+
+		default:
+			pendingComma = -1
+			afterValue = true
+		}
+	}
+
+	return out, nil
+}
+
+// unmarshalJSONC parses JSONC content into v.
+// Errors report the line and column in the original content.
+func unmarshalJSONC(data []byte, v any) error {
+	cleaned, err := stripJSONC(data)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(cleaned, v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// json reports the offset just past the offending byte
+			return newSyntaxError(data, int(syntaxErr.Offset)-1, syntaxErr.Error())
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return newSyntaxError(data, int(typeErr.Offset), typeErr.Error())
+		}
+		return err
+	}
+
+	return nil
+}
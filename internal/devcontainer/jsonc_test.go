@@ -0,0 +1,121 @@
+package devcontainer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripJSONC(t *testing.T) {
+	t.Run("keeps comment markers inside strings", func(t *testing.T) {
+		content := `{"url": "http://example.com/*path*/", "image": "mcr.microsoft.com/devcontainers/go"}`
+
+		cleaned, err := stripJSONC([]byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(cleaned))
+	})
+
+	t.Run("handles escaped quotes in strings", func(t *testing.T) {
+		content := `{"cmd": "echo \"// not a comment\"", // comment
+		}`
+
+		cleaned, err := stripJSONC([]byte(content))
+		require.NoError(t, err)
+
+		var v map[string]string
+		require.NoError(t, json.Unmarshal(cleaned, &v))
+		assert.Equal(t, `echo "// not a comment"`, v["cmd"])
+	})
+
+	t.Run("removes trailing commas separated by comments", func(t *testing.T) {
+		content := `{
+			"features": [
+				"a",
+				"b", /* last */
+			],
+			"name": "x", // trailing
+		}`
+
+		cleaned, err := stripJSONC([]byte(content))
+		require.NoError(t, err)
+
+		var v struct {
+			Features []string `json:"features"`
+			Name     string   `json:"name"`
+		}
+		require.NoError(t, json.Unmarshal(cleaned, &v))
+		assert.Equal(t, []string{"a", "b"}, v.Features)
+		assert.Equal(t, "x", v.Name)
+	})
+
+	t.Run("removes trailing commas after numbers and nested values", func(t *testing.T) {
+		cleaned, err := stripJSONC([]byte(`{"a": [1, 2,], "b": {"c": true,},}`))
+		require.NoError(t, err)
+
+		var v map[string]any
+		require.NoError(t, json.Unmarshal(cleaned, &v))
+		assert.Equal(t, map[string]any{"a": []any{1.0, 2.0}, "b": map[string]any{"c": true}}, v)
+	})
+
+	t.Run("keeps commas inside strings", func(t *testing.T) {
+		content := `{"args": "a,}"}`
+
+		cleaned, err := stripJSONC([]byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(cleaned))
+	})
+
+	t.Run("preserves offsets", func(t *testing.T) {
+		content := "{\n/* a\nb */\"image\": \"x\",\n}"
+
+		cleaned, err := stripJSONC([]byte(content))
+		require.NoError(t, err)
+		assert.Len(t, cleaned, len(content))
+		assert.Equal(t, "{\n    \n    \"image\": \"x\" \n}", string(cleaned))
+	})
+
+	t.Run("reports unterminated block comment with position", func(t *testing.T) {
+		content := "{\n  \"image\": \"x\" /* oops\n}"
+
+		_, err := stripJSONC([]byte(content))
+		var syntaxErr *SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, 2, syntaxErr.Line)
+		assert.Equal(t, 16, syntaxErr.Column)
+	})
+
+	t.Run("reports unterminated string with position", func(t *testing.T) {
+		content := "{\n\"image\": \"x}"
+
+		_, err := stripJSONC([]byte(content))
+		var syntaxErr *SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, 2, syntaxErr.Line)
+		assert.Equal(t, 10, syntaxErr.Column)
+	})
+}
+
+func TestUnmarshalJSONC(t *testing.T) {
+	t.Run("reports JSON syntax errors with line and column", func(t *testing.T) {
+		content := "{\n  // comment\n  \"image\": x\n}"
+
+		var v map[string]any
+		err := unmarshalJSONC([]byte(content), &v)
+
+		var syntaxErr *SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, 3, syntaxErr.Line)
+		assert.Equal(t, 12, syntaxErr.Column)
+	})
+	t.Run("rejects commas that do not follow a value", func(t *testing.T) {
+		for _, content := range []string{`[,]`, `{,}`, `[1,,]`, `{"a": 1,,}`, `[ /* c */ ,]`} {
+			var v any
+			err := unmarshalJSONC([]byte(content), &v)
+
+			var syntaxErr *SyntaxError
+			assert.ErrorAs(t, err, &syntaxErr, content)
+		}
+	})
+}
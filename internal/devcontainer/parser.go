@@ -5,24 +5,67 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strings"
+)
+
+// Kind describes how the container of a devcontainer is created.
+type Kind string
+
+const (
+	// KindImage uses a prebuilt image.
+	KindImage Kind = "image"
+	// KindDockerfile builds an image from a Dockerfile.
+	KindDockerfile Kind = "dockerfile"
+	// KindCompose uses Docker Compose.
+	KindCompose Kind = "compose"
+)
+
+// ShutdownAction describes what happens to a devcontainer when the editor closes.
+type ShutdownAction string
+
+const (
+	// ShutdownNone leaves the containers running.
+	ShutdownNone ShutdownAction = "none"
+	// ShutdownStopContainer stops the devcontainer's container; for compose,
+	// only the primary service.
+	ShutdownStopContainer ShutdownAction = "stopContainer"
+	// ShutdownStopCompose stops the whole compose project.
+	ShutdownStopCompose ShutdownAction = "stopCompose"
 )
 
 // Config represents a parsed devcontainer.json configuration.
 type Config struct {
-	Image             string   `json:"image"`
-	DockerComposeFile []string `json:"-"`
-	Service           string   `json:"service"`
-	ConfigPath        string   `json:"-"`
+	Image             string         `json:"image"`
+	Build             *BuildConfig   `json:"build"`
+	DockerComposeFile []string       `json:"-"`
+	Service           string         `json:"service"`
+	RunServices       []string       `json:"runServices"`
+	ShutdownAction    ShutdownAction `json:"shutdownAction"`
+	ConfigPath        string         `json:"-"`
+	Layout            Layout         `json:"-"`
+	// Warnings lists problems in the config that were worked around while parsing it.
+	Warnings []string `json:"-"`
+}
+
+// BuildConfig represents the build section of a devcontainer.json.
+// Paths are relative to the devcontainer.json.
+type BuildConfig struct {
+	Dockerfile string            `json:"dockerfile"`
+	Context    string            `json:"context"`
+	Args       map[string]string `json:"args"`
+	Target     string            `json:"target"`
 }
 
 // rawConfig is used for initial JSON unmarshaling to handle dockerComposeFile
 // which can be either a string or an array.
 type rawConfig struct {
 	Image             string          `json:"image"`
+	Build             *BuildConfig    `json:"build"`
+	DockerFile        string          `json:"dockerFile"`
+	Context           string          `json:"context"`
 	DockerComposeFile json.RawMessage `json:"dockerComposeFile"`
 	Service           string          `json:"service"`
+	RunServices       []string        `json:"runServices"`
+	ShutdownAction    ShutdownAction  `json:"shutdownAction"`
 }
 
 // ParseConfig reads and parses a devcontainer.json file.
@@ -33,18 +76,42 @@ func ParseConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Strip comments and trailing commas (JSONC support)
-	cleaned := stripJSONC(string(data))
-
 	var raw rawConfig
-	if err := json.Unmarshal([]byte(cleaned), &raw); err != nil {
+	if err := unmarshalJSONC(data, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
 	config := &Config{
-		Image:      raw.Image,
-		Service:    raw.Service,
-		ConfigPath: path,
+		Image:          raw.Image,
+		Build:          raw.Build,
+		Service:        raw.Service,
+		RunServices:    raw.RunServices,
+		ShutdownAction: raw.ShutdownAction,
+		ConfigPath:     path,
+		Layout:         DetectLayout(path),
+	}
+
+	// shutdownAction only adjusts how the devcontainer is stopped, so an unknown
+	// value falls back to the default rather than making the config unusable
+	switch config.ShutdownAction {
+	case "", ShutdownNone, ShutdownStopContainer, ShutdownStopCompose:
+	default:
+		config.Warnings = append(config.Warnings, fmt.Sprintf("unknown shutdownAction %q (must be %s, %s or %s), using the default",
+			config.ShutdownAction, ShutdownNone, ShutdownStopContainer, ShutdownStopCompose))
+		config.ShutdownAction = ""
+	}
+
+	// Legacy top-level dockerFile/context properties
+	if raw.DockerFile != "" {
+		if config.Build == nil {
+			config.Build = &BuildConfig{}
+		}
+		if config.Build.Dockerfile == "" {
+			config.Build.Dockerfile = raw.DockerFile
+		}
+		if config.Build.Context == "" {
+			config.Build.Context = raw.Context
+		}
 	}
 
 	// Parse dockerComposeFile (can be string or array)
@@ -75,26 +142,14 @@ func parseDockerComposeFile(data json.RawMessage) ([]string, error) {
 	return nil, fmt.Errorf("dockerComposeFile must be a string or array of strings")
 }
 
-// stripJSONC removes comments and trailing commas from JSONC content.
-func stripJSONC(content string) string {
-	// Remove single-line comments (// ...)
-	singleLineComment := regexp.MustCompile(`//[^\n]*`)
-	content = singleLineComment.ReplaceAllString(content, "")
-
-	// Remove multi-line comments (/* ... */)
-	multiLineComment := regexp.MustCompile(`/\*[\s\S]*?\*/`)
-	content = multiLineComment.ReplaceAllString(content, "")
-
-	// Remove trailing commas before } or ]
-	trailingComma := regexp.MustCompile(`,(\s*[}\]])`)
-	content = trailingComma.ReplaceAllString(content, "$1")
-
-	return strings.TrimSpace(content)
-}
-
 // IsImageBased returns true if the config uses an image directly.
 func (c *Config) IsImageBased() bool {
-	return c.Image != "" && len(c.DockerComposeFile) == 0
+	return c.Image != "" && len(c.DockerComposeFile) == 0 && !c.IsDockerfileBased()
+}
+
+// IsDockerfileBased returns true if the config builds its image from a Dockerfile.
+func (c *Config) IsDockerfileBased() bool {
+	return c.Build != nil && c.Build.Dockerfile != "" && len(c.DockerComposeFile) == 0
 }
 
 // IsComposeBased returns true if the config uses docker-compose.
@@ -102,6 +157,42 @@ func (c *Config) IsComposeBased() bool {
 	return len(c.DockerComposeFile) > 0
 }
 
+// Kind returns how the devcontainer's container is created.
+// Configs that specify neither a Dockerfile nor compose files are treated as image-based.
+func (c *Config) Kind() Kind {
+	switch {
+	case c.IsComposeBased():
+		return KindCompose
+	case c.IsDockerfileBased():
+		return KindDockerfile
+	default:
+		return KindImage
+	}
+}
+
+// GetShutdownAction returns the configured shutdownAction, or the spec's default
+// when it is not set: stopCompose for compose-based configs, stopContainer otherwise.
+func (c *Config) GetShutdownAction() ShutdownAction {
+	if c.ShutdownAction != "" {
+		return c.ShutdownAction
+	}
+	if c.IsComposeBased() {
+		return ShutdownStopCompose
+	}
+	return ShutdownStopContainer
+}
+
+// StopServices returns the compose services to stop when the devcontainer is shut
+// down, or nil for the whole project. With shutdownAction stopContainer the editor
+// only stops the primary service. Removing the devcontainer (down) always acts on
+// the whole project, since its networks and volumes are shared by every service.
+func (c *Config) StopServices(down bool) []string {
+	if down || !c.IsComposeBased() || c.Service == "" || c.GetShutdownAction() != ShutdownStopContainer {
+		return nil
+	}
+	return []string{c.Service}
+}
+
 // GetComposeFiles returns absolute paths of the compose files.
 func (c *Config) GetComposeFiles() []string {
 	if len(c.DockerComposeFile) == 0 {
@@ -126,3 +217,21 @@ func (c *Config) GetComposeFiles() []string {
 func (c *Config) GetConfigPath() string {
 	return c.ConfigPath
 }
+
+// WorkspaceFolder returns the workspace folder the devcontainer was opened from,
+// which the Dev Containers tooling records in the devcontainer.local_folder label.
+func (c *Config) WorkspaceFolder() string {
+	configDir := filepath.Dir(c.ConfigPath)
+
+	switch c.Layout {
+	case LayoutWorkspaceRoot:
+		// /foo/bar/.devcontainer.json
+		return configDir
+	case LayoutDevcontainerSubdir:
+		// /foo/bar/.devcontainer/app1/devcontainer.json
+		return filepath.Dir(filepath.Dir(configDir))
+	default:
+		// /foo/bar/.devcontainer/devcontainer.json
+		return filepath.Dir(configDir)
+	}
+}
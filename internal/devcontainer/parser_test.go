@@ -25,6 +25,7 @@ func TestParseConfig(t *testing.T) {
 		assert.Empty(t, config.DockerComposeFile)
 		assert.True(t, config.IsImageBased())
 		assert.False(t, config.IsComposeBased())
+		assert.Equal(t, KindImage, config.Kind())
 	})
 
 	t.Run("parses compose-based config with single file", func(t *testing.T) {
@@ -44,6 +45,7 @@ func TestParseConfig(t *testing.T) {
 		assert.Equal(t, "app", config.Service)
 		assert.False(t, config.IsImageBased())
 		assert.True(t, config.IsComposeBased())
+		assert.Equal(t, KindCompose, config.Kind())
 	})
 
 	t.Run("parses compose-based config with multiple files", func(t *testing.T) {
@@ -62,6 +64,50 @@ func TestParseConfig(t *testing.T) {
 		assert.True(t, config.IsComposeBased())
 	})
 
+	t.Run("parses Dockerfile-based config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "devcontainer.json")
+		content := `{
+			"name": "Build",
+			"build": {
+				"dockerfile": "Dockerfile",
+				"context": "..",
+				"args": {"VARIANT": "1.21"},
+				"target": "dev"
+			}
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := ParseConfig(configPath)
+		require.NoError(t, err)
+		require.NotNil(t, config.Build)
+		assert.Equal(t, "Dockerfile", config.Build.Dockerfile)
+		assert.Equal(t, "..", config.Build.Context)
+		assert.Equal(t, map[string]string{"VARIANT": "1.21"}, config.Build.Args)
+		assert.Equal(t, "dev", config.Build.Target)
+		assert.True(t, config.IsDockerfileBased())
+		assert.False(t, config.IsImageBased())
+		assert.False(t, config.IsComposeBased())
+		assert.Equal(t, KindDockerfile, config.Kind())
+	})
+
+	t.Run("parses legacy dockerFile property", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "devcontainer.json")
+		content := `{
+			"dockerFile": "Dockerfile.dev",
+			"context": ".."
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := ParseConfig(configPath)
+		require.NoError(t, err)
+		require.NotNil(t, config.Build)
+		assert.Equal(t, "Dockerfile.dev", config.Build.Dockerfile)
+		assert.Equal(t, "..", config.Build.Context)
+		assert.Equal(t, KindDockerfile, config.Kind())
+	})
+
 	t.Run("handles JSON with comments (JSONC)", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		configPath := filepath.Join(tmpDir, "devcontainer.json")
@@ -92,6 +138,20 @@ func TestParseConfig(t *testing.T) {
 		assert.Equal(t, "node:18", config.Image)
 	})
 
+	t.Run("keeps URLs in string values", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "devcontainer.json")
+		content := `{
+			"image": "mcr.microsoft.com/devcontainers/go:1", // image
+			"remoteEnv": {"URL": "http://x"},
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := ParseConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "mcr.microsoft.com/devcontainers/go:1", config.Image)
+	})
+
 	t.Run("returns error for non-existent file", func(t *testing.T) {
 		config, err := ParseConfig("/non/existent/devcontainer.json")
 		assert.Error(t, err)
@@ -118,6 +178,70 @@ func TestParseConfig(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, configPath, config.ConfigPath)
 	})
+
+	t.Run("parses shutdownAction and runServices", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "devcontainer.json")
+		content := `{
+			"dockerComposeFile": "docker-compose.yml",
+			"service": "app",
+			"runServices": ["app", "db"],
+			"shutdownAction": "stopContainer"
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := ParseConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app", "db"}, config.RunServices)
+		assert.Equal(t, ShutdownStopContainer, config.ShutdownAction)
+	})
+
+	t.Run("warns about an unknown shutdownAction and uses the default", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "devcontainer.json")
+		content := `{"dockerComposeFile": "docker-compose.yml", "service": "app", "shutdownAction": "stopEverything"}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := ParseConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, ShutdownStopCompose, config.GetShutdownAction())
+		require.Len(t, config.Warnings, 1)
+		assert.Contains(t, config.Warnings[0], `"stopEverything"`)
+	})
+}
+
+func TestConfig_GetShutdownAction(t *testing.T) {
+	assert.Equal(t, ShutdownStopCompose, (&Config{DockerComposeFile: []string{"docker-compose.yml"}}).GetShutdownAction())
+	assert.Equal(t, ShutdownStopContainer, (&Config{Image: "alpine"}).GetShutdownAction())
+	assert.Equal(t, ShutdownNone, (&Config{Image: "alpine", ShutdownAction: ShutdownNone}).GetShutdownAction())
+	assert.Equal(t, ShutdownStopContainer, (&Config{
+		DockerComposeFile: []string{"docker-compose.yml"},
+		ShutdownAction:    ShutdownStopContainer,
+	}).GetShutdownAction())
+}
+
+func TestConfig_StopServices(t *testing.T) {
+	stopContainer := &Config{
+		DockerComposeFile: []string{"docker-compose.yml"},
+		Service:           "app",
+		ShutdownAction:    ShutdownStopContainer,
+	}
+
+	t.Run("stopContainer stops only the primary service", func(t *testing.T) {
+		assert.Equal(t, []string{"app"}, stopContainer.StopServices(false))
+	})
+
+	t.Run("stopContainer with --down --volumes acts on the whole project", func(t *testing.T) {
+		assert.Nil(t, stopContainer.StopServices(true))
+	})
+
+	t.Run("stopCompose stops the whole project", func(t *testing.T) {
+		assert.Nil(t, (&Config{DockerComposeFile: []string{"docker-compose.yml"}, Service: "app"}).StopServices(false))
+	})
+
+	t.Run("image-based configs have no services", func(t *testing.T) {
+		assert.Nil(t, (&Config{Image: "alpine", ShutdownAction: ShutdownStopContainer}).StopServices(false))
+	})
 }
 
 func TestConfig_GetComposeFiles(t *testing.T) {
@@ -142,3 +266,20 @@ func TestConfig_GetComposeFiles(t *testing.T) {
 		assert.Equal(t, filepath.Join(devcontainerDir, "docker-compose.dev.yml"), files[1])
 	})
 }
+
+func TestConfig_WorkspaceFolder(t *testing.T) {
+	t.Run("standard layout", func(t *testing.T) {
+		config := &Config{ConfigPath: "/foo/bar/.devcontainer/devcontainer.json", Layout: LayoutDevcontainerDir}
+		assert.Equal(t, "/foo/bar", config.WorkspaceFolder())
+	})
+
+	t.Run("multi-config layout", func(t *testing.T) {
+		config := &Config{ConfigPath: "/foo/bar/.devcontainer/app1/devcontainer.json", Layout: LayoutDevcontainerSubdir}
+		assert.Equal(t, "/foo/bar", config.WorkspaceFolder())
+	})
+
+	t.Run("workspace root layout", func(t *testing.T) {
+		config := &Config{ConfigPath: "/foo/bar/.devcontainer.json", Layout: LayoutWorkspaceRoot}
+		assert.Equal(t, "/foo/bar", config.WorkspaceFolder())
+	})
+}
@@ -0,0 +1,93 @@
+package devcontainer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// DefaultIgnore lists the directories FindDevcontainerConfigsRecursive skips by default:
+// dependency and VCS directories, which are large and never hold a workspace's config.
+var DefaultIgnore = []string{"node_modules", ".git", "vendor"}
+
+// WalkOptions configures FindDevcontainerConfigsRecursive.
+type WalkOptions struct {
+	// MaxDepth is how many levels of subdirectories to descend into; 0 searches
+	// only the given directory, and a negative value means no limit.
+	MaxDepth int
+	// Ignore lists glob patterns, as for filepath.Match, of directory names to skip.
+	Ignore []string
+}
+
+// FindDevcontainerConfigsRecursive searches the given directory and its subdirectories
+// for devcontainer config files, in every location FindDevcontainerConfigs looks at.
+// Directories are read concurrently. Directories that cannot be read are skipped,
+// and symbolic links are not followed. The results are sorted by path.
+func FindDevcontainerConfigsRecursive(dir string, opts WalkOptions) ([]Candidate, error) {
+	for _, pattern := range opts.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dir)
+	}
+
+	var (
+		mu      sync.Mutex
+		configs []Candidate
+		wg      sync.WaitGroup
+		// Limits how many directories are read at once
+		sem = make(chan struct{}, 4*runtime.NumCPU())
+	)
+
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		defer wg.Done()
+
+		sem <- struct{}{}
+		found, findErr := FindDevcontainerConfigs(dir)
+		entries, readErr := os.ReadDir(dir)
+		<-sem
+
+		if findErr == nil && len(found) > 0 {
+			mu.Lock()
+			configs = append(configs, found...)
+			mu.Unlock()
+		}
+		if readErr != nil || (opts.MaxDepth >= 0 && depth >= opts.MaxDepth) {
+			return
+		}
+
+		for _, entry := range entries {
+			// DirEntry does not follow symbolic links, so links cannot cause cycles
+			if !entry.IsDir() || entry.Name() == ".devcontainer" || ignored(entry.Name(), opts.Ignore) {
+				continue
+			}
+			wg.Add(1)
+			go walk(filepath.Join(dir, entry.Name()), depth+1)
+		}
+	}
+
+	wg.Add(1)
+	walk(dir, 0)
+	wg.Wait()
+
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Path < configs[j].Path
+	})
+	return configs, nil
+}
+
+// ignored returns true if the directory name matches any of the patterns.
+func ignored(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package devcontainer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig writes a minimal devcontainer config at root/rel and returns its path.
+func writeConfig(t *testing.T, root, rel string) string {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(`{"image": "alpine"}`), 0644))
+	return path
+}
+
+func TestFindDevcontainerConfigsRecursive(t *testing.T) {
+	root := t.TempDir()
+	api := writeConfig(t, root, "api/.devcontainer/devcontainer.json")
+	web := writeConfig(t, root, "org/web/.devcontainer.json")
+	worker := writeConfig(t, root, "org/services/worker/.devcontainer/worker/devcontainer.json")
+	writeConfig(t, root, "web/node_modules/pkg/.devcontainer/devcontainer.json")
+	writeConfig(t, root, "api/vendor/lib/.devcontainer.json")
+	writeConfig(t, root, "api/.git/modules/sub/.devcontainer.json")
+
+	t.Run("finds configs at any depth, skipping ignored directories", func(t *testing.T) {
+		configs, err := FindDevcontainerConfigsRecursive(root, WalkOptions{MaxDepth: -1, Ignore: DefaultIgnore})
+		require.NoError(t, err)
+
+		require.Len(t, configs, 3)
+		assert.Equal(t, Candidate{Path: api, Layout: LayoutDevcontainerDir}, configs[0])
+		assert.Equal(t, Candidate{Path: worker, Layout: LayoutDevcontainerSubdir}, configs[1])
+		assert.Equal(t, Candidate{Path: web, Layout: LayoutWorkspaceRoot}, configs[2])
+	})
+
+	t.Run("stops at the maximum depth", func(t *testing.T) {
+		configs, err := FindDevcontainerConfigsRecursive(root, WalkOptions{MaxDepth: 2, Ignore: DefaultIgnore})
+		require.NoError(t, err)
+
+		require.Len(t, configs, 2)
+		assert.Equal(t, api, configs[0].Path)
+		assert.Equal(t, web, configs[1].Path)
+	})
+
+	t.Run("depth 0 searches only the directory itself", func(t *testing.T) {
+		configs, err := FindDevcontainerConfigsRecursive(filepath.Join(root, "api"), WalkOptions{})
+		require.NoError(t, err)
+
+		require.Len(t, configs, 1)
+		assert.Equal(t, api, configs[0].Path)
+	})
+
+	t.Run("ignores directories matching globs", func(t *testing.T) {
+		configs, err := FindDevcontainerConfigsRecursive(root, WalkOptions{MaxDepth: -1, Ignore: []string{"node_modules", "vendor", ".git", "o*"}})
+		require.NoError(t, err)
+
+		require.Len(t, configs, 1)
+		assert.Equal(t, api, configs[0].Path)
+	})
+
+	t.Run("finds configs with the same subfolder name in different workspaces", func(t *testing.T) {
+		root := t.TempDir()
+		a := writeConfig(t, root, "a/.devcontainer/python/devcontainer.json")
+		b := writeConfig(t, root, "b/.devcontainer/python/devcontainer.json")
+
+		configs, err := FindDevcontainerConfigsRecursive(root, WalkOptions{MaxDepth: -1, Ignore: DefaultIgnore})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Candidate{
+			{Path: a, Layout: LayoutDevcontainerSubdir},
+			{Path: b, Layout: LayoutDevcontainerSubdir},
+		}, configs)
+	})
+
+	t.Run("rejects invalid ignore patterns", func(t *testing.T) {
+		_, err := FindDevcontainerConfigsRecursive(root, WalkOptions{Ignore: []string{"["}})
+		assert.Error(t, err)
+	})
+
+	t.Run("returns error for non-existent directory", func(t *testing.T) {
+		_, err := FindDevcontainerConfigsRecursive(filepath.Join(root, "missing"), WalkOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestFindDevcontainerConfigsRecursive_SkipsUnreadableDirectories(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced")
+	}
+
+	root := t.TempDir()
+	api := writeConfig(t, root, "api/.devcontainer/devcontainer.json")
+	writeConfig(t, root, "private/repo/.devcontainer.json")
+
+	private := filepath.Join(root, "private")
+	require.NoError(t, os.Chmod(private, 0))
+	t.Cleanup(func() { _ = os.Chmod(private, 0755) })
+
+	configs, err := FindDevcontainerConfigsRecursive(root, WalkOptions{MaxDepth: -1})
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, api, configs[0].Path)
+}
@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// volumeMetadataName is the name of the archive entry describing the backed up volume.
+// It comes first, followed by the volume's contents under "volume/".
+const volumeMetadataName = "dcstop-volume.json"
+
+// volumeMetadata describes a backed up volume.
+type volumeMetadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// BackupVolume archives a volume, with its name and labels, to a gzipped tarball
+// in dir and returns the path of the archive. The archive is only left in dir
+// once it is complete.
+func (c *ComposeOps) BackupVolume(ctx context.Context, volume VolumeInfo, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	content, err := c.client.VolumeArchive(ctx, volume.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read volume: %w", err)
+	}
+	defer func() { _ = content.Close() }()
+
+	f, err := os.CreateTemp(dir, "."+volume.Name+"-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	if err := writeVolumeArchive(f, volume, content); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.tar.gz", volume.Name, time.Now().Format("20060102T150405")))
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+	return path, nil
+}
+
+// writeVolumeArchive writes the metadata entry followed by the entries of content to w, gzipped.
+func writeVolumeArchive(w io.Writer, volume VolumeInfo, content io.Reader) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	metadata, err := json.Marshal(volumeMetadata{Name: volume.Name, Labels: volume.Labels})
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    volumeMetadataName,
+		Mode:    0o644,
+		Size:    int64(len(metadata)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(metadata); err != nil {
+		return err
+	}
+
+	if err := copyTar(tw, tar.NewReader(content)); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// RestoreVolume recreates a volume, with its labels, from an archive made by
+// BackupVolume. If name is not empty, the volume is restored under that name.
+// It returns the restored volume. Existing volumes are never overwritten.
+func (c *ComposeOps) RestoreVolume(ctx context.Context, path, name string) (VolumeInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return VolumeInfo{}, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return VolumeInfo{}, fmt.Errorf("failed to read archive %s: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != volumeMetadataName {
+		return VolumeInfo{}, fmt.Errorf("%s is not a volume backup made by dcstop", path)
+	}
+	var metadata volumeMetadata
+	if err := json.NewDecoder(tr).Decode(&metadata); err != nil {
+		return VolumeInfo{}, fmt.Errorf("failed to read volume metadata from %s: %w", path, err)
+	}
+
+	volume := VolumeInfo{Name: metadata.Name, Labels: metadata.Labels, Size: -1}
+	if name != "" {
+		volume.Name = name
+	}
+
+	// Stream the remaining entries to the daemon as an archive of their own
+	pr, pw := io.Pipe()
+	copied := make(chan error, 1)
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := copyTar(tw, tr)
+		if err == nil {
+			err = tw.Close()
+		}
+		_ = pw.CloseWithError(err)
+		copied <- err
+	}()
+
+	err = c.client.VolumeRestore(ctx, volume, pr)
+	// Unblock the copy if the daemon stopped reading early, and wait for it
+	// to finish with the archive before it is closed
+	_ = pr.CloseWithError(errRestoreFinished)
+	copyErr := <-copied
+	if err != nil {
+		return VolumeInfo{}, fmt.Errorf("failed to restore volume %s: %w", volume.Name, err)
+	}
+	if copyErr != nil && !errors.Is(copyErr, errRestoreFinished) {
+		return VolumeInfo{}, fmt.Errorf("failed to read archive %s: %w", path, copyErr)
+	}
+	return volume, nil
+}
+
+// errRestoreFinished stops copying an archive the daemon no longer reads.
+var errRestoreFinished = errors.New("volume restore finished")
+
+// copyTar copies every entry of tr to tw.
+func copyTar(tw *tar.Writer, tr *tar.Reader) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// volumeTar returns a tar archive with the given files under "volume/", as VolumeArchive streams it.
+func volumeTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "volume/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "volume/" + name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// readTar returns the regular files in a tar archive by name.
+func readTar(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(content)
+	}
+}
+
+func TestBackupAndRestoreVolume(t *testing.T) {
+	dir := t.TempDir()
+	volume := VolumeInfo{
+		Name:   "myproject_data",
+		Labels: map[string]string{LabelComposeProject: "myproject", "com.docker.compose.volume": "data"},
+	}
+
+	mockClient := new(MockComposeClient)
+	mockClient.On("VolumeArchive", mock.Anything, "myproject_data").
+		Return(io.NopCloser(bytes.NewReader(volumeTar(t, map[string]string{"seed.sql": "INSERT 1"}))), nil)
+
+	ops := NewComposeOps(mockClient)
+	path, err := ops.BackupVolume(context.Background(), volume, dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(path))
+	assert.Regexp(t, `^myproject_data-\d{8}T\d{6}\.tar\.gz$`, filepath.Base(path))
+
+	// Only the finished archive is left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	t.Run("restores the volume with its labels", func(t *testing.T) {
+		var restored map[string]string
+		mockClient.On("VolumeRestore", mock.Anything, VolumeInfo{Name: "myproject_data", Labels: volume.Labels, Size: -1}, mock.Anything).
+			Run(func(args mock.Arguments) {
+				restored = readTar(t, bytes.NewReader(args.Get(2).([]byte)))
+			}).Return(nil).Once()
+
+		got, err := ops.RestoreVolume(context.Background(), path, "")
+		require.NoError(t, err)
+		assert.Equal(t, "myproject_data", got.Name)
+		assert.Equal(t, map[string]string{"volume/seed.sql": "INSERT 1"}, restored)
+	})
+
+	t.Run("restores under another name", func(t *testing.T) {
+		mockClient.On("VolumeRestore", mock.Anything, mock.MatchedBy(func(v VolumeInfo) bool {
+			return v.Name == "myproject_data_copy"
+		}), mock.Anything).Return(nil).Once()
+
+		got, err := ops.RestoreVolume(context.Background(), path, "myproject_data_copy")
+		require.NoError(t, err)
+		assert.Equal(t, "myproject_data_copy", got.Name)
+	})
+
+	t.Run("reports restore errors", func(t *testing.T) {
+		mockClient.On("VolumeRestore", mock.Anything, mock.Anything, mock.Anything).
+			Return(errors.New("volume myproject_data already exists")).Once()
+
+		_, err := ops.RestoreVolume(context.Background(), path, "")
+		assert.ErrorContains(t, err, "already exists")
+	})
+}
+
+func TestRestoreVolume_RejectsOtherArchives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("not gzip"), 0o644))
+
+	_, err := NewComposeOps(new(MockComposeClient)).RestoreVolume(context.Background(), path, "")
+	assert.Error(t, err)
+}
+
+func TestBackupVolume_LeavesNoPartialArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	mockClient := new(MockComposeClient)
+	mockClient.On("VolumeArchive", mock.Anything, "myproject_data").
+		Return(io.NopCloser(bytes.NewReader([]byte("truncated"))), nil)
+
+	_, err := NewComposeOps(mockClient).BackupVolume(context.Background(), VolumeInfo{Name: "myproject_data"}, dir)
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -6,11 +6,16 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
@@ -165,10 +170,12 @@ func (c *RealDockerClient) ContainerList(ctx context.Context, options ContainerL
 	result := make([]ContainerInfo, len(containers))
 	for i, cont := range containers {
 		result[i] = ContainerInfo{
-			ID:     cont.ID,
-			Names:  cont.Names,
-			Labels: cont.Labels,
-			State:  cont.State,
+			ID:      cont.ID,
+			Names:   cont.Names,
+			Labels:  cont.Labels,
+			State:   cont.State,
+			Status:  cont.Status,
+			ImageID: cont.ImageID,
 		}
 	}
 
@@ -176,12 +183,89 @@ func (c *RealDockerClient) ContainerList(ctx context.Context, options ContainerL
 }
 
 // ContainerStop stops a container.
-func (c *RealDockerClient) ContainerStop(ctx context.Context, containerID string, timeout *int) error {
-	opts := container.StopOptions{}
-	if timeout != nil {
-		opts.Timeout = timeout
+func (c *RealDockerClient) ContainerStop(ctx context.Context, containerID string, options ContainerStopOptions) error {
+	return c.cli.ContainerStop(ctx, containerID, container.StopOptions{
+		Signal:  options.Signal,
+		Timeout: options.Timeout,
+	})
+}
+
+// ContainerStart starts a stopped container.
+func (c *RealDockerClient) ContainerStart(ctx context.Context, containerID string) error {
+	return c.cli.ContainerStart(ctx, containerID, container.StartOptions{})
+}
+
+// ContainerEvents streams container events until ctx is done or the stream fails.
+func (c *RealDockerClient) ContainerEvents(ctx context.Context) (<-chan ContainerEvent, <-chan error) {
+	messages, errs := c.cli.Events(ctx, events.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("type", string(events.ContainerEventType))),
+	})
+
+	out := make(chan ContainerEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-messages:
+				select {
+				case out <- ContainerEvent{ID: msg.Actor.ID, Action: string(msg.Action)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+// ContainerCPUPercent samples a container's CPU usage, as a percentage of one CPU
+// the way `docker stats` reports it. The daemon takes two readings about a second apart.
+func (c *RealDockerClient) ContainerCPUPercent(ctx context.Context, containerID string) (float64, error) {
+	resp, err := c.cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0, fmt.Errorf("failed to decode stats: %w", err)
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	cpus := float64(stats.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0, nil
+	}
+	return cpuDelta / systemDelta * cpus * 100, nil
+}
+
+// ContainerExecSessions returns the number of exec sessions running in a container.
+func (c *RealDockerClient) ContainerExecSessions(ctx context.Context, containerID string) (int, error) {
+	info, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	// ExecIDs also lists exec sessions that have ended but not been cleaned up yet
+	running := 0
+	for _, id := range info.ExecIDs {
+		exec, err := c.cli.ContainerExecInspect(ctx, id)
+		if err != nil {
+			continue
+		}
+		if exec.Running {
+			running++
+		}
 	}
-	return c.cli.ContainerStop(ctx, containerID, opts)
+	return running, nil
 }
 
 // ContainerRemove removes a container.
@@ -245,13 +329,219 @@ func (c *RealDockerClient) VolumeList(ctx context.Context, options VolumeListOpt
 		result[i] = VolumeInfo{
 			Name:   vol.Name,
 			Labels: vol.Labels,
+			Size:   -1,
+		}
+	}
+
+	return result, nil
+}
+
+// ImageList lists images matching the given options.
+func (c *RealDockerClient) ImageList(ctx context.Context, options ImageListOptions) ([]ImageInfo, error) {
+	filterArgs := filters.NewArgs()
+	if options.LabelFilter != "" {
+		filterArgs.Add("label", options.LabelFilter)
+	}
+
+	images, err := c.cli.ImageList(ctx, image.ListOptions{
+		Filters: filterArgs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ImageInfo, len(images))
+	for i, img := range images {
+		result[i] = ImageInfo{
+			ID:          img.ID,
+			RepoTags:    img.RepoTags,
+			RepoDigests: img.RepoDigests,
+			Size:        img.Size,
+			// Always -1 (unknown) here, as the daemon only calculates it for disk
+			// usage; UniqueSize treats that like 0, so this only keeps the field
+			// consistent with its documentation
+			SharedSize: img.SharedSize,
 		}
 	}
 
 	return result, nil
 }
 
+// ImageRemove removes an image and its untagged parents.
+func (c *RealDockerClient) ImageRemove(ctx context.Context, imageID string, force bool) error {
+	_, err := c.cli.ImageRemove(ctx, imageID, image.RemoveOptions{
+		Force:         force,
+		PruneChildren: true,
+	})
+	return err
+}
+
+// volumeMountPath is where helper containers mount a volume. Archives made by
+// VolumeArchive have their entries under its base name.
+const volumeMountPath = "/volume"
+
+// createVolumeHelper creates a container with the volume mounted at volumeMountPath,
+// for copying files in and out of the volume through the daemon. The container is
+// never started, so any local image will do.
+func (c *RealDockerClient) createVolumeHelper(ctx context.Context, volumeName string, readOnly bool) (string, error) {
+	images, err := c.cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list images: %w", err)
+	}
+	if len(images) == 0 {
+		return "", fmt.Errorf("no local image to create a helper container from")
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx,
+		&container.Config{
+			Image:  images[0].ID,
+			Cmd:    []string{"true"},
+			Labels: map[string]string{"dcstop.helper": "volume"},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{{
+				Type:     mount.TypeVolume,
+				Source:   volumeName,
+				Target:   volumeMountPath,
+				ReadOnly: readOnly,
+			}},
+		},
+		nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to create helper container: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// helperArchive is the archive of a volume, which removes the helper container when closed.
+type helperArchive struct {
+	io.ReadCloser
+	remove func()
+}
+
+func (a *helperArchive) Close() error {
+	err := a.ReadCloser.Close()
+	a.remove()
+	return err
+}
+
+// VolumeArchive streams the contents of a volume as a tar archive, with the
+// entries under the directory "volume".
+func (c *RealDockerClient) VolumeArchive(ctx context.Context, volumeName string) (io.ReadCloser, error) {
+	id, err := c.createVolumeHelper(ctx, volumeName, true)
+	if err != nil {
+		return nil, err
+	}
+	remove := func() {
+		_ = c.cli.ContainerRemove(context.WithoutCancel(ctx), id, container.RemoveOptions{Force: true})
+	}
+
+	content, _, err := c.cli.CopyFromContainer(ctx, id, volumeMountPath)
+	if err != nil {
+		remove()
+		return nil, err
+	}
+	return &helperArchive{ReadCloser: content, remove: remove}, nil
+}
+
+// VolumeRestore creates a volume and extracts a tar archive made by VolumeArchive into it.
+// It fails if the volume already exists, and removes the volume again if extracting fails.
+func (c *RealDockerClient) VolumeRestore(ctx context.Context, vol VolumeInfo, archive io.Reader) error {
+	if _, err := c.cli.VolumeInspect(ctx, vol.Name); err == nil {
+		return fmt.Errorf("volume %s already exists", vol.Name)
+	} else if !client.IsErrNotFound(err) {
+		return err
+	}
+
+	if _, err := c.cli.VolumeCreate(ctx, volume.CreateOptions{Name: vol.Name, Labels: vol.Labels}); err != nil {
+		return fmt.Errorf("failed to create volume: %w", err)
+	}
+
+	err := c.extractToVolume(ctx, vol.Name, archive)
+	if err != nil {
+		_ = c.cli.VolumeRemove(context.WithoutCancel(ctx), vol.Name, true)
+	}
+	return err
+}
+
+// extractToVolume extracts a tar archive made by VolumeArchive into a volume.
+func (c *RealDockerClient) extractToVolume(ctx context.Context, volumeName string, archive io.Reader) error {
+	id, err := c.createVolumeHelper(ctx, volumeName, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.cli.ContainerRemove(context.WithoutCancel(ctx), id, container.RemoveOptions{Force: true})
+	}()
+
+	// Entries are under "volume/", so extracting at the root fills the mount
+	return c.cli.CopyToContainer(ctx, id, "/", archive, container.CopyToContainerOptions{CopyUIDGID: true})
+}
+
 // VolumeRemove removes a volume.
 func (c *RealDockerClient) VolumeRemove(ctx context.Context, volumeName string, force bool) error {
 	return c.cli.VolumeRemove(ctx, volumeName, force)
 }
+
+// DiskUsage returns disk usage data for the object types selected in options.
+func (c *RealDockerClient) DiskUsage(ctx context.Context, options DiskUsageOptions) (*DiskUsage, error) {
+	var objects []types.DiskUsageObject
+	if options.Containers {
+		objects = append(objects, types.ContainerObject)
+	}
+	if options.Images {
+		objects = append(objects, types.ImageObject)
+	}
+	if options.Volumes {
+		objects = append(objects, types.VolumeObject)
+	}
+	if options.BuildCache {
+		objects = append(objects, types.BuildCacheObject)
+	}
+
+	du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{Types: objects})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiskUsage{}
+	for _, cont := range du.Containers {
+		result.Containers = append(result.Containers, ContainerInfo{
+			ID:      cont.ID,
+			Names:   cont.Names,
+			Labels:  cont.Labels,
+			State:   cont.State,
+			Status:  cont.Status,
+			ImageID: cont.ImageID,
+			SizeRw:  cont.SizeRw,
+		})
+	}
+	for _, img := range du.Images {
+		result.Images = append(result.Images, ImageInfo{
+			ID:          img.ID,
+			RepoTags:    img.RepoTags,
+			RepoDigests: img.RepoDigests,
+			Size:        img.Size,
+			SharedSize:  img.SharedSize,
+		})
+	}
+	for _, vol := range du.Volumes {
+		size := int64(-1)
+		if vol.UsageData != nil {
+			size = vol.UsageData.Size
+		}
+		result.Volumes = append(result.Volumes, VolumeInfo{
+			Name:   vol.Name,
+			Labels: vol.Labels,
+			Size:   size,
+		})
+	}
+	// As with `docker system df`, shared records are counted with the images
+	for _, record := range du.BuildCache {
+		if !record.Shared {
+			result.BuildCacheSize += record.Size
+		}
+	}
+
+	return result, nil
+}
@@ -3,6 +3,7 @@ package docker
 import (
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 )
@@ -19,9 +20,11 @@ type NetworkListOptions struct {
 }
 
 // VolumeInfo represents volume information.
+// Size is in bytes and is only set from disk usage data; -1 means unknown.
 type VolumeInfo struct {
 	Name   string
 	Labels map[string]string
+	Size   int64
 }
 
 // VolumeListOptions represents options for listing volumes.
@@ -29,103 +32,153 @@ type VolumeListOptions struct {
 	LabelFilter string
 }
 
-// ComposeClient extends ContainerClient with network and volume operations.
+// ImageInfo represents image information.
+// Size is in bytes. SharedSize is the part of it shared with other images,
+// or -1 if unknown.
+type ImageInfo struct {
+	ID          string
+	RepoTags    []string
+	RepoDigests []string
+	Size        int64
+	SharedSize  int64
+}
+
+// ImageListOptions represents options for listing images.
+type ImageListOptions struct {
+	LabelFilter string
+}
+
+// ComposeClient extends ContainerClient with network, volume and image operations.
 type ComposeClient interface {
 	ContainerClient
 	NetworkList(ctx context.Context, options NetworkListOptions) ([]NetworkInfo, error)
 	NetworkRemove(ctx context.Context, networkID string) error
 	VolumeList(ctx context.Context, options VolumeListOptions) ([]VolumeInfo, error)
 	VolumeRemove(ctx context.Context, volumeName string, force bool) error
+	ImageList(ctx context.Context, options ImageListOptions) ([]ImageInfo, error)
+	ImageRemove(ctx context.Context, imageID string, force bool) error
+	// VolumeArchive streams the contents of a volume as a tar archive,
+	// with the entries under the directory "volume".
+	VolumeArchive(ctx context.Context, volumeName string) (io.ReadCloser, error)
+	// VolumeRestore creates a volume and extracts a tar archive made by VolumeArchive into it.
+	VolumeRestore(ctx context.Context, volume VolumeInfo, archive io.Reader) error
+	DiskUsage(ctx context.Context, options DiskUsageOptions) (*DiskUsage, error)
 }
 
 // ComposeOps provides operations on Docker Compose projects.
 type ComposeOps struct {
-	client ComposeClient
+	client    ComposeClient
+	parallel  int
+	stopOpts  ContainerStopOptions
+	backupDir string
 }
 
 // NewComposeOps creates a new ComposeOps with the given client.
 func NewComposeOps(client ComposeClient) *ComposeOps {
-	return &ComposeOps{client: client}
+	return &ComposeOps{client: client, parallel: DefaultParallel}
+}
+
+// SetParallel sets how many containers are stopped or removed at once.
+// Values below 1 mean one at a time.
+func (c *ComposeOps) SetParallel(n int) {
+	c.parallel = n
+}
+
+// SetStopOptions sets how containers are stopped. The options that are set
+// take precedence over per-service settings from compose files.
+func (c *ComposeOps) SetStopOptions(opts ContainerStopOptions) {
+	c.stopOpts = opts
+}
+
+// SetBackupDir sets the directory volumes are archived to before PlanDown's
+// plans remove them. An empty dir means volumes are removed without a backup.
+func (c *ComposeOps) SetBackupDir(dir string) {
+	c.backupDir = dir
 }
 
 // FindComposeContainers finds containers belonging to a compose project.
 func (c *ComposeOps) FindComposeContainers(ctx context.Context, projectName string) ([]ContainerInfo, error) {
 	opts := ContainerListOptions{
 		All:         true,
-		LabelFilter: fmt.Sprintf("com.docker.compose.project=%s", projectName),
+		LabelFilter: fmt.Sprintf("%s=%s", LabelComposeProject, projectName),
 	}
 
 	return c.client.ContainerList(ctx, opts)
 }
 
-// StopComposeProject stops all containers in a compose project.
-func (c *ComposeOps) StopComposeProject(ctx context.Context, projectName string) error {
-	containers, err := c.FindComposeContainers(ctx, projectName)
+// FindComposeContainersByConfigFiles finds compose containers whose config_files
+// label contains any of the given compose files. Unlike FindComposeContainers it
+// does not depend on the project name, which may have been set by means dcstop
+// cannot see (e.g. `docker compose -p`).
+func (c *ComposeOps) FindComposeContainersByConfigFiles(ctx context.Context, composeFiles []string) ([]ContainerInfo, error) {
+	containers, err := c.client.ContainerList(ctx, ContainerListOptions{
+		All:         true,
+		LabelFilter: LabelComposeConfigFiles,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to find compose containers: %w", err)
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(composeFiles))
+	for _, f := range composeFiles {
+		wanted[filepath.Clean(f)] = true
 	}
 
+	var result []ContainerInfo
 	for _, container := range containers {
-		if err := c.client.ContainerStop(ctx, container.ID, nil); err != nil {
-			return fmt.Errorf("failed to stop container %s: %w", container.ID, err)
+		for _, f := range composeConfigFiles(container) {
+			if wanted[f] {
+				result = append(result, container)
+				break
+			}
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-// DownComposeProject stops and removes containers and networks for a compose project.
-func (c *ComposeOps) DownComposeProject(ctx context.Context, projectName string, removeVolumes bool) error {
-	containers, err := c.FindComposeContainers(ctx, projectName)
-	if err != nil {
-		return fmt.Errorf("failed to find compose containers: %w", err)
+// composeConfigFiles returns the compose files recorded in a container's labels.
+// Relative paths are resolved against the project's working directory.
+func composeConfigFiles(container ContainerInfo) []string {
+	label := container.Labels[LabelComposeConfigFiles]
+	if label == "" {
+		return nil
 	}
 
-	// Stop containers
-	for _, container := range containers {
-		if err := c.client.ContainerStop(ctx, container.ID, nil); err != nil {
-			return fmt.Errorf("failed to stop container %s: %w", container.ID, err)
+	workingDir := container.Labels[LabelComposeWorkingDir]
+	files := strings.Split(label, ",")
+	for i, f := range files {
+		f = strings.TrimSpace(f)
+		if !filepath.IsAbs(f) && workingDir != "" {
+			f = filepath.Join(workingDir, f)
 		}
+		files[i] = filepath.Clean(f)
 	}
 
-	// Remove containers
-	for _, container := range containers {
-		if err := c.client.ContainerRemove(ctx, container.ID, true); err != nil {
-			return fmt.Errorf("failed to remove container %s: %w", container.ID, err)
-		}
-	}
+	return files
+}
 
-	// Remove networks
-	networks, err := c.client.NetworkList(ctx, NetworkListOptions{
-		LabelFilter: fmt.Sprintf("com.docker.compose.project=%s", projectName),
-	})
+// StopComposeProject stops all containers in a compose project.
+func (c *ComposeOps) StopComposeProject(ctx context.Context, projectName string) error {
+	plan, err := c.PlanStop(ctx, projectName)
 	if err != nil {
-		return fmt.Errorf("failed to list networks: %w", err)
+		return err
 	}
 
-	for _, network := range networks {
-		if err := c.client.NetworkRemove(ctx, network.ID); err != nil {
-			return fmt.Errorf("failed to remove network %s: %w", network.Name, err)
-		}
-	}
-
-	// Remove volumes if requested
-	if removeVolumes {
-		volumes, err := c.client.VolumeList(ctx, VolumeListOptions{
-			LabelFilter: fmt.Sprintf("com.docker.compose.project=%s", projectName),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to list volumes: %w", err)
-		}
+	_, err = c.Apply(ctx, plan)
+	return err
+}
 
-		for _, volume := range volumes {
-			if err := c.client.VolumeRemove(ctx, volume.Name, true); err != nil {
-				return fmt.Errorf("failed to remove volume %s: %w", volume.Name, err)
-			}
-		}
+// DownComposeProject stops and removes containers and networks for a compose project.
+// With removeVolumes the volumes are removed too, after archiving them if SetBackupDir was used.
+func (c *ComposeOps) DownComposeProject(ctx context.Context, projectName string, removeVolumes bool) error {
+	plan, err := c.PlanDown(ctx, projectName, removeVolumes)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	_, err = c.Apply(ctx, plan)
+	return err
 }
 
 // DeriveDevcontainerProjectName derives a compose project name from a devcontainer.json path.
@@ -138,6 +191,10 @@ func (c *ComposeOps) DownComposeProject(ctx context.Context, projectName string,
 // For multi-config layout (/foo/bar/.devcontainer/app1/devcontainer.json):
 //
 //	project name will be "app1"
+//
+// For workspace root layout (/foo/bar/.devcontainer.json):
+//
+//	project name will be "bar"
 func DeriveDevcontainerProjectName(configPath string) string {
 	// Get the directory containing devcontainer.json
 	configDir := filepath.Dir(configPath)
@@ -150,12 +207,14 @@ func DeriveDevcontainerProjectName(configPath string) string {
 		// Use the parent directory name (bar) + "_devcontainer"
 		parentDir := filepath.Dir(configDir)
 		name := filepath.Base(parentDir)
-		return strings.ToLower(name) + "_devcontainer"
+		return NormalizeProjectName(name + "_devcontainer")
 	}
 
 	// Multi-config layout: /foo/bar/.devcontainer/app1/devcontainer.json
 	// Use the subdirectory name only (app1)
-	return strings.ToLower(configDirName)
+	// Workspace root layout: /foo/bar/.devcontainer.json
+	// Use the workspace directory name (bar)
+	return NormalizeProjectName(configDirName)
 }
 
 // DeriveProjectNameFromComposeFile derives a compose project name from a docker-compose file path.
@@ -191,7 +250,7 @@ func DeriveProjectNameFromComposeFile(composeFilePath string) string {
 
 	// If .devcontainer is not in the path, use the compose file's directory name
 	if devcontainerIndex == -1 {
-		return strings.ToLower(composeDirName)
+		return NormalizeProjectName(composeDirName)
 	}
 
 	// If compose file is directly in .devcontainer directory
@@ -199,7 +258,7 @@ func DeriveProjectNameFromComposeFile(composeFilePath string) string {
 		// Get the parent directory of .devcontainer
 		if devcontainerIndex > 0 {
 			parentDirName := pathParts[devcontainerIndex-1]
-			return strings.ToLower(parentDirName) + "_devcontainer"
+			return NormalizeProjectName(parentDirName + "_devcontainer")
 		}
 	}
 
@@ -207,11 +266,11 @@ func DeriveProjectNameFromComposeFile(composeFilePath string) string {
 	// Use the subdirectory name (the directory immediately after .devcontainer)
 	if devcontainerIndex < len(pathParts)-2 {
 		subdirName := pathParts[devcontainerIndex+1]
-		return strings.ToLower(subdirName)
+		return NormalizeProjectName(subdirName)
 	}
 
 	// Fallback to compose directory name
-	return strings.ToLower(composeDirName)
+	return NormalizeProjectName(composeDirName)
 }
 
 // DeriveProjectNameFromComposeFiles derives a compose project name from a list of compose files.
@@ -233,13 +292,21 @@ type Config interface {
 }
 
 // DeriveProjectNameFromConfig derives a compose project name from a devcontainer config.
-// For compose-based configs, it uses the location of the docker-compose file.
+// For compose-based configs, the name is resolved from the compose files the way
+// Docker Compose does (see ComposeProject.ProjectName), falling back to the
+// location of the first docker-compose file.
 // For image-based configs, it falls back to the devcontainer.json path.
 func DeriveProjectNameFromConfig(cfg Config) string {
 	if cfg.IsComposeBased() {
 		composeFiles := cfg.GetComposeFiles()
 		if len(composeFiles) > 0 {
-			return DeriveProjectNameFromComposeFile(composeFiles[0])
+			fallback := DeriveProjectNameFromComposeFile(composeFiles[0])
+
+			project, err := LoadComposeProject(composeFiles)
+			if err != nil {
+				return fallback
+			}
+			return project.ProjectName(fallback)
 		}
 	}
 	// Fallback to devcontainer.json path for image-based or when compose files are not found
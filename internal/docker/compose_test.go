@@ -2,9 +2,13 @@ package docker
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dev-shimada/dcstop/internal/devcontainer"
 	"github.com/stretchr/testify/assert"
@@ -43,6 +47,46 @@ func (m *MockComposeClient) VolumeRemove(ctx context.Context, volumeName string,
 	return args.Error(0)
 }
 
+func (m *MockComposeClient) ImageList(ctx context.Context, options ImageListOptions) ([]ImageInfo, error) {
+	args := m.Called(ctx, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ImageInfo), args.Error(1)
+}
+
+func (m *MockComposeClient) ImageRemove(ctx context.Context, imageID string, force bool) error {
+	args := m.Called(ctx, imageID, force)
+	return args.Error(0)
+}
+
+func (m *MockComposeClient) VolumeArchive(ctx context.Context, volumeName string) (io.ReadCloser, error) {
+	args := m.Called(ctx, volumeName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+// VolumeRestore reads the archive before recording the call, so the recorded
+// argument is the archive's content rather than a reader still being written to.
+func (m *MockComposeClient) VolumeRestore(ctx context.Context, volume VolumeInfo, archive io.Reader) error {
+	content, err := io.ReadAll(archive)
+	if err != nil {
+		return err
+	}
+	args := m.Called(ctx, volume, content)
+	return args.Error(0)
+}
+
+func (m *MockComposeClient) DiskUsage(ctx context.Context, options DiskUsageOptions) (*DiskUsage, error) {
+	args := m.Called(ctx, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*DiskUsage), args.Error(1)
+}
+
 func TestFindComposeContainers(t *testing.T) {
 	t.Run("finds containers by compose project name", func(t *testing.T) {
 		mockClient := new(MockComposeClient)
@@ -81,6 +125,67 @@ func TestFindComposeContainers(t *testing.T) {
 	})
 }
 
+func TestFindComposeContainersByConfigFiles(t *testing.T) {
+	t.Run("matches containers by config_files label", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		containers := []ContainerInfo{
+			{
+				ID: "web123",
+				Labels: map[string]string{
+					"com.docker.compose.project":              "renamed",
+					"com.docker.compose.project.config_files": "/home/user/myproject/docker-compose.yml,/home/user/myproject/docker-compose.dev.yml",
+					"com.docker.compose.project.working_dir":  "/home/user/myproject",
+				},
+			},
+			{
+				ID: "rel456",
+				Labels: map[string]string{
+					"com.docker.compose.project":              "renamed",
+					"com.docker.compose.project.config_files": "docker-compose.dev.yml",
+					"com.docker.compose.project.working_dir":  "/home/user/myproject",
+				},
+			},
+			{
+				ID: "other789",
+				Labels: map[string]string{
+					"com.docker.compose.project":              "other",
+					"com.docker.compose.project.config_files": "/home/user/other/docker-compose.yml",
+				},
+			},
+		}
+
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{
+			All:         true,
+			LabelFilter: "com.docker.compose.project.config_files",
+		}).Return(containers, nil)
+
+		ops := NewComposeOps(mockClient)
+		result, err := ops.FindComposeContainersByConfigFiles(context.Background(), []string{"/home/user/myproject/docker-compose.dev.yml"})
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "web123", result[0].ID)
+		assert.Equal(t, "rel456", result[1].ID)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("returns empty when no container matches", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{
+			{ID: "other789", Labels: map[string]string{"com.docker.compose.project.config_files": "/home/user/other/docker-compose.yml"}},
+		}, nil)
+
+		ops := NewComposeOps(mockClient)
+		result, err := ops.FindComposeContainersByConfigFiles(context.Background(), []string{"/home/user/myproject/docker-compose.yml"})
+
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		mockClient.AssertExpectations(t)
+	})
+}
+
 func TestStopComposeProject(t *testing.T) {
 	t.Run("stops all containers in compose project", func(t *testing.T) {
 		mockClient := new(MockComposeClient)
@@ -100,6 +205,82 @@ func TestStopComposeProject(t *testing.T) {
 		require.NoError(t, err)
 		mockClient.AssertExpectations(t)
 	})
+
+	t.Run("stops containers concurrently up to the parallel limit", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		var containers []ContainerInfo
+		for i := range 8 {
+			containers = append(containers, ContainerInfo{ID: fmt.Sprintf("svc%d", i)})
+		}
+
+		var inFlight, maxInFlight atomic.Int32
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return(containers, nil)
+		mockClient.On("ContainerStop", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+			n := inFlight.Add(1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			inFlight.Add(-1)
+		}).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		ops.SetParallel(3)
+		err := ops.StopComposeProject(context.Background(), "myproject")
+
+		require.NoError(t, err)
+		mockClient.AssertNumberOfCalls(t, "ContainerStop", 8)
+		assert.Greater(t, maxInFlight.Load(), int32(1))
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	})
+
+	t.Run("stops one container at a time with a limit of 1", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		containers := []ContainerInfo{{ID: "web123"}, {ID: "db456"}, {ID: "cache789"}}
+
+		var inFlight, maxInFlight atomic.Int32
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return(containers, nil)
+		mockClient.On("ContainerStop", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+			if n := inFlight.Add(1); n > maxInFlight.Load() {
+				maxInFlight.Store(n)
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		ops.SetParallel(1)
+		err := ops.StopComposeProject(context.Background(), "myproject")
+
+		require.NoError(t, err)
+		mockClient.AssertNumberOfCalls(t, "ContainerStop", 3)
+		assert.Equal(t, int32(1), maxInFlight.Load())
+	})
+
+	t.Run("does not start stopping containers after cancellation", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		containers := []ContainerInfo{{ID: "web123"}, {ID: "db456"}, {ID: "cache789"}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return(containers, nil)
+		// The first stop cancels the run; the others must not be started
+		mockClient.On("ContainerStop", mock.Anything, "web123", mock.Anything).Run(func(mock.Arguments) {
+			cancel()
+		}).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		ops.SetParallel(1)
+		err := ops.StopComposeProject(ctx, "myproject")
+
+		require.ErrorIs(t, err, context.Canceled)
+		mockClient.AssertNumberOfCalls(t, "ContainerStop", 1)
+	})
 }
 
 func TestDownComposeProject(t *testing.T) {
@@ -189,6 +370,11 @@ func TestDeriveDevcontainerProjectName(t *testing.T) {
 		name := DeriveDevcontainerProjectName("/home/user/MyProject/.devcontainer/MyApp/devcontainer.json")
 		assert.Equal(t, "myapp", name)
 	})
+
+	t.Run("workspace root layout - uses workspace directory name", func(t *testing.T) {
+		name := DeriveDevcontainerProjectName("/home/user/MyProject/.devcontainer.json")
+		assert.Equal(t, "myproject", name)
+	})
 }
 
 func TestDeriveProjectNameFromComposeFile(t *testing.T) {
@@ -262,6 +448,7 @@ func TestDeriveProjectNameFromComposeFiles(t *testing.T) {
 
 func TestDeriveProjectNameFromConfig(t *testing.T) {
 	t.Run("compose-based config with compose file in .devcontainer", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
 		tmpDir := t.TempDir()
 		devcontainerDir := filepath.Join(tmpDir, ".devcontainer")
 		require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
@@ -283,6 +470,7 @@ func TestDeriveProjectNameFromConfig(t *testing.T) {
 	})
 
 	t.Run("compose-based config with compose file outside .devcontainer", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
 		tmpDir := t.TempDir()
 		devcontainerDir := filepath.Join(tmpDir, ".devcontainer")
 		require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
@@ -303,6 +491,7 @@ func TestDeriveProjectNameFromConfig(t *testing.T) {
 	})
 
 	t.Run("compose-based config with compose file in subdirectory", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
 		tmpDir := t.TempDir()
 		devcontainerDir := filepath.Join(tmpDir, ".devcontainer", "app1")
 		require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
@@ -321,6 +510,51 @@ func TestDeriveProjectNameFromConfig(t *testing.T) {
 		assert.Equal(t, "app1", projectName)
 	})
 
+	t.Run("workspace root config with compose file in .devcontainer", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		devcontainerDir := filepath.Join(tmpDir, ".devcontainer")
+		require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
+
+		configPath := filepath.Join(tmpDir, ".devcontainer.json")
+		content := `{
+			"dockerComposeFile": ".devcontainer/docker-compose.yml",
+			"service": "app"
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := devcontainer.ParseConfig(configPath)
+		require.NoError(t, err)
+
+		// The compose file's directory is <workspace>/.devcontainer, as for a
+		// .devcontainer/devcontainer.json next to it
+		projectName := DeriveProjectNameFromConfig(config)
+		assert.Equal(t, NormalizeProjectName(filepath.Base(tmpDir))+"_devcontainer", projectName)
+	})
+
+	t.Run("compose-based config uses name from compose file", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		devcontainerDir := filepath.Join(tmpDir, ".devcontainer")
+		require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
+
+		composePath := filepath.Join(devcontainerDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composePath, []byte("name: custom-project\n"), 0644))
+
+		configPath := filepath.Join(devcontainerDir, "devcontainer.json")
+		content := `{
+			"dockerComposeFile": "docker-compose.yml",
+			"service": "app"
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		config, err := devcontainer.ParseConfig(configPath)
+		require.NoError(t, err)
+
+		projectName := DeriveProjectNameFromConfig(config)
+		assert.Equal(t, "custom-project", projectName)
+	})
+
 	t.Run("image-based config falls back to devcontainer.json path", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		devcontainerDir := filepath.Join(tmpDir, ".devcontainer")
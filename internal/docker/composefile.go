@@ -0,0 +1,306 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeProject represents the merged contents of one or more compose files.
+type ComposeProject struct {
+	// Name is the top-level name: from the compose files, before interpolation.
+	Name string
+	// WorkingDir is the project directory, i.e. the directory of the first compose file.
+	WorkingDir string
+	// Env holds the variables from the .env file in the project directory.
+	Env map[string]string
+	// Services holds the merged service definitions, keyed by service name.
+	Services map[string]*ComposeService
+}
+
+// ComposeService represents the parts of a compose service that dcstop uses.
+type ComposeService struct {
+	// DependsOn lists the services this service depends on.
+	DependsOn []string
+	// StopTimeout is stop_grace_period in whole seconds, or nil if unset.
+	StopTimeout *int
+	// StopSignal is stop_signal, or empty if unset.
+	StopSignal string
+}
+
+// composeFile represents the parts of a single compose file that dcstop uses.
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+}
+
+// composeService represents the parts of a service in a single compose file that dcstop uses.
+type composeService struct {
+	DependsOn       dependsOn `yaml:"depends_on"`
+	StopGracePeriod string    `yaml:"stop_grace_period"`
+	StopSignal      string    `yaml:"stop_signal"`
+}
+
+// dependsOn accepts both the short (list) and long (mapping) depends_on syntax.
+type dependsOn []string
+
+func (d *dependsOn) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var services []string
+		if err := node.Decode(&services); err != nil {
+			return err
+		}
+		*d = services
+	case yaml.MappingNode:
+		// Mapping keys and values alternate in Content
+		services := make([]string, 0, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			services = append(services, node.Content[i].Value)
+		}
+		*d = services
+	default:
+		return fmt.Errorf("line %d: depends_on must be a list or a mapping", node.Line)
+	}
+	return nil
+}
+
+// LoadComposeProject reads and merges the given compose files.
+// Later files override earlier ones, as with `docker compose -f a.yml -f b.yml`.
+// Files that do not exist are skipped.
+func LoadComposeProject(files []string) (*ComposeProject, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no compose files given")
+	}
+
+	project := &ComposeProject{
+		WorkingDir: filepath.Dir(files[0]),
+		Services:   make(map[string]*ComposeService),
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read compose file: %w", err)
+		}
+
+		var file composeFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse compose file %s: %w", path, err)
+		}
+
+		if file.Name != "" {
+			project.Name = file.Name
+		}
+
+		for name, svc := range file.Services {
+			merged, ok := project.Services[name]
+			if !ok {
+				merged = &ComposeService{}
+				project.Services[name] = merged
+			}
+			// Compose merges depends_on across files rather than replacing it
+			for _, dep := range svc.DependsOn {
+				if !slices.Contains(merged.DependsOn, dep) {
+					merged.DependsOn = append(merged.DependsOn, dep)
+				}
+			}
+
+			if svc.StopGracePeriod != "" {
+				timeout, err := parseStopGracePeriod(svc.StopGracePeriod)
+				if err != nil {
+					return nil, fmt.Errorf("invalid stop_grace_period for service %s in %s: %w", name, path, err)
+				}
+				merged.StopTimeout = &timeout
+			}
+			if svc.StopSignal != "" {
+				merged.StopSignal = svc.StopSignal
+			}
+		}
+	}
+
+	env, err := readEnvFile(filepath.Join(project.WorkingDir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+	project.Env = env
+
+	return project, nil
+}
+
+// Dependencies returns the depends_on graph of the project's services,
+// mapping each service to the services it depends on.
+func (p *ComposeProject) Dependencies() map[string][]string {
+	deps := make(map[string][]string, len(p.Services))
+	for name, svc := range p.Services {
+		deps[name] = svc.DependsOn
+	}
+	return deps
+}
+
+// StopOptions returns how the containers of each service are stopped, from
+// stop_grace_period and stop_signal. Services that set neither are omitted.
+func (p *ComposeProject) StopOptions() map[string]ContainerStopOptions {
+	opts := make(map[string]ContainerStopOptions)
+	for name, svc := range p.Services {
+		if svc.StopTimeout != nil || svc.StopSignal != "" {
+			opts[name] = ContainerStopOptions{Timeout: svc.StopTimeout, Signal: svc.StopSignal}
+		}
+	}
+	return opts
+}
+
+// parseStopGracePeriod parses a compose duration such as "1m30s" into whole
+// seconds, rounding up. A bare number is taken as seconds.
+func parseStopGracePeriod(value string) (int, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("negative duration %q", value)
+		}
+		return seconds, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", value)
+	}
+	return int((d + time.Second - 1) / time.Second), nil
+}
+
+// lookupEnv looks up a variable in the process environment first,
+// then in the project's .env file.
+func (p *ComposeProject) lookupEnv(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	value, ok := p.Env[key]
+	return value, ok
+}
+
+// ProjectName resolves the compose project name using Docker Compose's precedence:
+//
+//  1. COMPOSE_PROJECT_NAME in the environment
+//  2. COMPOSE_PROJECT_NAME in the .env file of the project directory
+//  3. the top-level name: in the compose files (interpolated)
+//  4. fallback
+//
+// The result is normalized with NormalizeProjectName.
+func (p *ComposeProject) ProjectName(fallback string) string {
+	if name := os.Getenv("COMPOSE_PROJECT_NAME"); name != "" {
+		return NormalizeProjectName(name)
+	}
+	if name := p.Env["COMPOSE_PROJECT_NAME"]; name != "" {
+		return NormalizeProjectName(name)
+	}
+
+	if p.Name != "" {
+		if name := NormalizeProjectName(interpolate(p.Name, p.lookupEnv)); name != "" {
+			return name
+		}
+	}
+
+	return NormalizeProjectName(fallback)
+}
+
+// projectNameChars matches the characters Docker Compose allows in a project name.
+var projectNameChars = regexp.MustCompile(`[a-z0-9_-]`)
+
+// NormalizeProjectName normalizes a project name the way Docker Compose does:
+// it lowercases the name, drops every character other than a-z, 0-9, "_" and "-",
+// and strips leading "_" and "-".
+func NormalizeProjectName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Join(projectNameChars.FindAllString(name, -1), "")
+	return strings.TrimLeft(name, "_-")
+}
+
+// interpolationPattern matches $$, $VAR, ${VAR}, ${VAR:-default} and ${VAR-default}.
+var interpolationPattern = regexp.MustCompile(`\$\$|\$([A-Za-z_][A-Za-z0-9_]*)|\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?-)([^}]*))?\}`)
+
+// interpolate substitutes variables in s the way compose files are interpolated.
+// Unset variables without a default are replaced with an empty string.
+func interpolate(s string, lookup func(string) (string, bool)) string {
+	return interpolationPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		groups := interpolationPattern.FindStringSubmatch(match)
+		key := groups[1]
+		if key == "" {
+			key = groups[2]
+		}
+
+		value, ok := lookup(key)
+		switch groups[3] {
+		case ":-":
+			if !ok || value == "" {
+				return groups[4]
+			}
+		case "-":
+			if !ok {
+				return groups[4]
+			}
+		}
+		return value
+	})
+}
+
+// readEnvFile reads KEY=VALUE pairs from a .env file.
+// A missing file yields an empty map.
+func readEnvFile(path string) (map[string]string, error) {
+	env := make(map[string]string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return env, nil
+		}
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		// Strip matching quotes, otherwise drop inline comments
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	return env, nil
+}
@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeProjectName(t *testing.T) {
+	assert.Equal(t, "myproject", NormalizeProjectName("MyProject"))
+	assert.Equal(t, "myproject_devcontainer", NormalizeProjectName("my.project_devcontainer"))
+	assert.Equal(t, "devcontainer", NormalizeProjectName(".devcontainer"))
+	assert.Equal(t, "app-1", NormalizeProjectName("_-App-1"))
+	assert.Equal(t, "", NormalizeProjectName("..."))
+}
+
+func TestInterpolate(t *testing.T) {
+	lookup := func(key string) (string, bool) {
+		env := map[string]string{"NAME": "proj", "EMPTY": ""}
+		value, ok := env[key]
+		return value, ok
+	}
+
+	assert.Equal(t, "proj", interpolate("$NAME", lookup))
+	assert.Equal(t, "proj-dev", interpolate("${NAME}-dev", lookup))
+	assert.Equal(t, "fallback", interpolate("${MISSING:-fallback}", lookup))
+	assert.Equal(t, "fallback", interpolate("${EMPTY:-fallback}", lookup))
+	assert.Equal(t, "", interpolate("${EMPTY-fallback}", lookup))
+	assert.Equal(t, "$NAME", interpolate("$$NAME", lookup))
+	assert.Equal(t, "", interpolate("$MISSING", lookup))
+}
+
+func TestComposeProjectName(t *testing.T) {
+	t.Run("uses top-level name from compose file", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("name: My.App\nservices: {}\n"), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+		assert.Equal(t, "myapp", project.ProjectName("fallback"))
+	})
+
+	t.Run("later compose files override name", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		base := filepath.Join(tmpDir, "docker-compose.yml")
+		override := filepath.Join(tmpDir, "docker-compose.override.yml")
+		require.NoError(t, os.WriteFile(base, []byte("name: base\n"), 0644))
+		require.NoError(t, os.WriteFile(override, []byte("name: override\n"), 0644))
+
+		project, err := LoadComposeProject([]string{base, override})
+		require.NoError(t, err)
+		assert.Equal(t, "override", project.ProjectName("fallback"))
+	})
+
+	t.Run("interpolates name with variables from .env", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("name: ${APP_NAME:-app}-dev\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("APP_NAME=shop\n"), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+		assert.Equal(t, "shop-dev", project.ProjectName("fallback"))
+	})
+
+	t.Run(".env COMPOSE_PROJECT_NAME takes precedence over name", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("name: fromfile\n"), 0644))
+		envContent := "# comment\nexport COMPOSE_PROJECT_NAME=\"FromEnvFile\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte(envContent), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+		assert.Equal(t, "fromenvfile", project.ProjectName("fallback"))
+	})
+
+	t.Run("environment takes precedence over .env", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "fromenv")
+		tmpDir := t.TempDir()
+		composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("name: fromfile\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("COMPOSE_PROJECT_NAME=fromenvfile\n"), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+		assert.Equal(t, "fromenv", project.ProjectName("fallback"))
+	})
+
+	t.Run("falls back when no name is set", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
+		tmpDir := t.TempDir()
+		composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("services:\n  app:\n    image: alpine\n"), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+		assert.Equal(t, "my_fallback", project.ProjectName("My_Fallback"))
+	})
+
+	t.Run("skips missing compose files", func(t *testing.T) {
+		project, err := LoadComposeProject([]string{"/non/existent/docker-compose.yml"})
+		require.NoError(t, err)
+		assert.Empty(t, project.Name)
+	})
+
+	t.Run("returns error for invalid YAML", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("name: [unclosed\n"), 0644))
+
+		_, err := LoadComposeProject([]string{composeFile})
+		assert.Error(t, err)
+	})
+}
+
+func TestComposeProjectDependencies(t *testing.T) {
+	t.Run("reads short and long depends_on syntax", func(t *testing.T) {
+		composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+		content := `services:
+  app:
+    depends_on:
+      - db
+      - cache
+  worker:
+    depends_on:
+      db:
+        condition: service_healthy
+  db: {}
+  cache: {}
+`
+		require.NoError(t, os.WriteFile(composeFile, []byte(content), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+
+		deps := project.Dependencies()
+		assert.Equal(t, []string{"db", "cache"}, deps["app"])
+		assert.Equal(t, []string{"db"}, deps["worker"])
+		assert.Empty(t, deps["db"])
+	})
+
+	t.Run("merges depends_on across compose files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		base := filepath.Join(tmpDir, "docker-compose.yml")
+		override := filepath.Join(tmpDir, "docker-compose.override.yml")
+		require.NoError(t, os.WriteFile(base, []byte("services:\n  app:\n    depends_on: [db]\n"), 0644))
+		require.NoError(t, os.WriteFile(override, []byte("services:\n  app:\n    depends_on: [db, cache]\n"), 0644))
+
+		project, err := LoadComposeProject([]string{base, override})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db", "cache"}, project.Dependencies()["app"])
+	})
+
+	t.Run("rejects invalid depends_on", func(t *testing.T) {
+		composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("services:\n  app:\n    depends_on: db\n"), 0644))
+
+		_, err := LoadComposeProject([]string{composeFile})
+		assert.Error(t, err)
+	})
+}
+
+func TestComposeProjectStopOptions(t *testing.T) {
+	t.Run("reads stop_grace_period and stop_signal", func(t *testing.T) {
+		composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+		content := `services:
+  jvm:
+    stop_grace_period: 1m
+  worker:
+    stop_grace_period: 1.5s
+    stop_signal: SIGINT
+  scratch:
+    stop_grace_period: "0"
+  db: {}
+`
+		require.NoError(t, os.WriteFile(composeFile, []byte(content), 0644))
+
+		project, err := LoadComposeProject([]string{composeFile})
+		require.NoError(t, err)
+
+		opts := project.StopOptions()
+		require.Contains(t, opts, "jvm")
+		assert.Equal(t, 60, *opts["jvm"].Timeout)
+		assert.Empty(t, opts["jvm"].Signal)
+		assert.Equal(t, 2, *opts["worker"].Timeout)
+		assert.Equal(t, "SIGINT", opts["worker"].Signal)
+		assert.Equal(t, 0, *opts["scratch"].Timeout)
+		assert.NotContains(t, opts, "db")
+	})
+
+	t.Run("later compose files override stop settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		base := filepath.Join(tmpDir, "docker-compose.yml")
+		override := filepath.Join(tmpDir, "docker-compose.override.yml")
+		require.NoError(t, os.WriteFile(base, []byte("services:\n  app:\n    stop_grace_period: 10s\n    stop_signal: SIGTERM\n"), 0644))
+		require.NoError(t, os.WriteFile(override, []byte("services:\n  app:\n    stop_grace_period: 30s\n"), 0644))
+
+		project, err := LoadComposeProject([]string{base, override})
+		require.NoError(t, err)
+
+		opts := project.StopOptions()["app"]
+		assert.Equal(t, 30, *opts.Timeout)
+		assert.Equal(t, "SIGTERM", opts.Signal)
+	})
+
+	t.Run("rejects an invalid stop_grace_period", func(t *testing.T) {
+		composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte("services:\n  app:\n    stop_grace_period: soon\n"), 0644))
+
+		_, err := LoadComposeProject([]string{composeFile})
+		assert.ErrorContains(t, err, "stop_grace_period")
+	})
+}
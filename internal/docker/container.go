@@ -2,15 +2,48 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+)
+
+// Labels set on containers by the Dev Containers tooling and Docker Compose.
+const (
+	LabelConfigFile     = "devcontainer.config_file"
+	LabelLocalFolder    = "devcontainer.local_folder"
+	LabelComposeProject = "com.docker.compose.project"
+
+	LabelComposeConfigFiles = "com.docker.compose.project.config_files"
+	LabelComposeWorkingDir  = "com.docker.compose.project.working_dir"
+	LabelComposeService     = "com.docker.compose.service"
 )
 
 // ContainerInfo represents container information.
+// SizeRw is the size of the writable layer in bytes and is only set from disk usage data.
 type ContainerInfo struct {
-	ID     string
-	Names  []string
-	Labels map[string]string
-	State  string
+	ID      string
+	Names   []string
+	Labels  map[string]string
+	State   string
+	Status  string
+	ImageID string
+	SizeRw  int64
+}
+
+// Name returns the primary container name without the leading slash.
+func (c ContainerInfo) Name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// ShortID returns the first 12 characters of the container ID.
+func (c ContainerInfo) ShortID() string {
+	if len(c.ID) > 12 {
+		return c.ID[:12]
+	}
+	return c.ID
 }
 
 // ContainerListOptions represents options for listing containers.
@@ -19,30 +52,66 @@ type ContainerListOptions struct {
 	LabelFilter string
 }
 
+// ContainerStopOptions represents options for stopping a container.
+// Zero values leave the choice to the container's own configuration,
+// i.e. its StopSignal and StopTimeout, which the daemon applies.
+type ContainerStopOptions struct {
+	// Timeout is the number of seconds to wait before killing the container.
+	Timeout *int
+	// Signal is the signal sent to stop the container, e.g. "SIGTERM".
+	Signal string
+}
+
+// withDefaults returns o with its unset fields taken from defaults.
+func (o ContainerStopOptions) withDefaults(defaults ContainerStopOptions) ContainerStopOptions {
+	if o.Timeout == nil {
+		o.Timeout = defaults.Timeout
+	}
+	if o.Signal == "" {
+		o.Signal = defaults.Signal
+	}
+	return o
+}
+
 // ContainerClient is an interface for Docker container operations.
 // This interface allows for easy mocking in tests.
 type ContainerClient interface {
 	ContainerList(ctx context.Context, options ContainerListOptions) ([]ContainerInfo, error)
-	ContainerStop(ctx context.Context, containerID string, timeout *int) error
+	ContainerStop(ctx context.Context, containerID string, options ContainerStopOptions) error
+	ContainerStart(ctx context.Context, containerID string) error
 	ContainerRemove(ctx context.Context, containerID string, force bool) error
 	Close() error
 }
 
 // ContainerOps provides operations on containers.
 type ContainerOps struct {
-	client ContainerClient
+	client   ContainerClient
+	parallel int
+	stopOpts ContainerStopOptions
 }
 
 // NewContainerOps creates a new ContainerOps with the given client.
 func NewContainerOps(client ContainerClient) *ContainerOps {
-	return &ContainerOps{client: client}
+	return &ContainerOps{client: client, parallel: DefaultParallel}
+}
+
+// SetParallel sets how many containers are stopped or removed at once.
+// Values below 1 mean one at a time.
+func (c *ContainerOps) SetParallel(n int) {
+	c.parallel = n
+}
+
+// SetStopOptions sets how containers are stopped. The options that are set
+// take precedence over per-service settings from compose files.
+func (c *ContainerOps) SetStopOptions(opts ContainerStopOptions) {
+	c.stopOpts = opts
 }
 
 // FindDevcontainersByFolder finds devcontainers by the local folder path.
 func (c *ContainerOps) FindDevcontainersByFolder(ctx context.Context, folderPath string) ([]ContainerInfo, error) {
 	opts := ContainerListOptions{
 		All:         true,
-		LabelFilter: fmt.Sprintf("devcontainer.local_folder=%s", folderPath),
+		LabelFilter: fmt.Sprintf("%s=%s", LabelLocalFolder, folderPath),
 	}
 
 	return c.client.ContainerList(ctx, opts)
@@ -52,28 +121,78 @@ func (c *ContainerOps) FindDevcontainersByFolder(ctx context.Context, folderPath
 func (c *ContainerOps) FindDevcontainersByConfigPath(ctx context.Context, configPath string) ([]ContainerInfo, error) {
 	opts := ContainerListOptions{
 		All:         true,
-		LabelFilter: fmt.Sprintf("devcontainer.config_file=%s", configPath),
+		LabelFilter: fmt.Sprintf("%s=%s", LabelConfigFile, configPath),
 	}
 
 	return c.client.ContainerList(ctx, opts)
 }
 
-// StopContainers stops the specified containers.
+// ListDevcontainers lists every container on the daemon that carries a
+// devcontainer or compose project label. Containers matching more than one
+// label are returned only once.
+func (c *ContainerOps) ListDevcontainers(ctx context.Context) ([]ContainerInfo, error) {
+	seen := make(map[string]bool)
+	var result []ContainerInfo
+
+	for _, label := range []string{LabelConfigFile, LabelLocalFolder, LabelComposeProject} {
+		// A label filter without a value matches any container that has the label
+		containers, err := c.client.ContainerList(ctx, ContainerListOptions{
+			All:         true,
+			LabelFilter: label,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers with label %s: %w", label, err)
+		}
+
+		for _, container := range containers {
+			if seen[container.ID] {
+				continue
+			}
+			seen[container.ID] = true
+			result = append(result, container)
+		}
+	}
+
+	return result, nil
+}
+
+// StopContainers stops the specified containers concurrently.
+// Every container is attempted; the errors of those that failed are joined.
 func (c *ContainerOps) StopContainers(ctx context.Context, containers []ContainerInfo) error {
-	for _, container := range containers {
-		if err := c.client.ContainerStop(ctx, container.ID, nil); err != nil {
-			return fmt.Errorf("failed to stop container %s: %w", container.ID, err)
+	var errs []error
+	for i, err := range forEachContainer(ctx, c.parallel, containers, c.stopper(nil)) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop container %s: %w", containers[i].ID, err))
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-// RemoveContainers removes the specified containers.
+// RemoveContainers removes the specified containers concurrently.
+// Every container is attempted; the errors of those that failed are joined.
 func (c *ContainerOps) RemoveContainers(ctx context.Context, containers []ContainerInfo) error {
-	for _, container := range containers {
-		if err := c.client.ContainerRemove(ctx, container.ID, true); err != nil {
-			return fmt.Errorf("failed to remove container %s: %w", container.ID, err)
+	var errs []error
+	for i, err := range forEachContainer(ctx, c.parallel, containers, c.remove) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove container %s: %w", containers[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopper returns a function that stops a single container, using the stop
+// options set on c and then the plan's per-service options, if a plan is given.
+func (c *ContainerOps) stopper(plan *Plan) func(context.Context, ContainerInfo) error {
+	return func(ctx context.Context, container ContainerInfo) error {
+		opts := c.stopOpts
+		if plan != nil {
+			opts = opts.withDefaults(plan.StopOptions[container.Labels[LabelComposeService]])
 		}
+		return c.client.ContainerStop(ctx, container.ID, opts)
 	}
-	return nil
+}
+
+// remove force-removes a single container.
+func (c *ContainerOps) remove(ctx context.Context, container ContainerInfo) error {
+	return c.client.ContainerRemove(ctx, container.ID, true)
 }
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,8 +23,13 @@ func (m *MockContainerClient) ContainerList(ctx context.Context, options Contain
 	return args.Get(0).([]ContainerInfo), args.Error(1)
 }
 
-func (m *MockContainerClient) ContainerStop(ctx context.Context, containerID string, timeout *int) error {
-	args := m.Called(ctx, containerID, timeout)
+func (m *MockContainerClient) ContainerStop(ctx context.Context, containerID string, options ContainerStopOptions) error {
+	args := m.Called(ctx, containerID, options)
+	return args.Error(0)
+}
+
+func (m *MockContainerClient) ContainerStart(ctx context.Context, containerID string) error {
+	args := m.Called(ctx, containerID)
 	return args.Error(0)
 }
 
@@ -128,6 +134,30 @@ func TestStopContainers(t *testing.T) {
 		require.NoError(t, err)
 		mockClient.AssertExpectations(t)
 	})
+
+	t.Run("attempts every container and joins the errors", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		containers := []ContainerInfo{
+			{ID: "container1", Names: []string{"/dev1"}},
+			{ID: "container2", Names: []string{"/dev2"}},
+			{ID: "container3", Names: []string{"/dev3"}},
+		}
+
+		err1 := errors.New("timeout")
+		err3 := errors.New("no such container")
+		mockClient.On("ContainerStop", mock.Anything, "container1", mock.Anything).Return(err1)
+		mockClient.On("ContainerStop", mock.Anything, "container2", mock.Anything).Return(nil)
+		mockClient.On("ContainerStop", mock.Anything, "container3", mock.Anything).Return(err3)
+
+		ops := NewContainerOps(mockClient)
+		err := ops.StopContainers(context.Background(), containers)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, err1)
+		assert.ErrorIs(t, err, err3)
+		mockClient.AssertExpectations(t)
+	})
 }
 
 func TestRemoveContainers(t *testing.T) {
@@ -146,4 +176,57 @@ func TestRemoveContainers(t *testing.T) {
 		require.NoError(t, err)
 		mockClient.AssertExpectations(t)
 	})
+
+	t.Run("attempts every container after a failure", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		containers := []ContainerInfo{
+			{ID: "container1", Names: []string{"/dev1"}},
+			{ID: "container2", Names: []string{"/dev2"}},
+		}
+
+		removeErr := errors.New("removal in progress")
+		mockClient.On("ContainerRemove", mock.Anything, "container1", true).Return(removeErr)
+		mockClient.On("ContainerRemove", mock.Anything, "container2", true).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		err := ops.RemoveContainers(context.Background(), containers)
+
+		require.ErrorIs(t, err, removeErr)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestListDevcontainers(t *testing.T) {
+	t.Run("merges containers matched by any devcontainer label", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		imageContainer := ContainerInfo{
+			ID: "abc123",
+			Labels: map[string]string{
+				"devcontainer.local_folder": "/home/user/myproject",
+				"devcontainer.config_file":  "/home/user/myproject/.devcontainer/devcontainer.json",
+			},
+		}
+		composeContainer := ContainerInfo{
+			ID:     "def456",
+			Labels: map[string]string{"com.docker.compose.project": "other"},
+		}
+
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file"}).
+			Return([]ContainerInfo{imageContainer}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.local_folder"}).
+			Return([]ContainerInfo{imageContainer}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "com.docker.compose.project"}).
+			Return([]ContainerInfo{composeContainer}, nil)
+
+		ops := NewContainerOps(mockClient)
+		result, err := ops.ListDevcontainers(context.Background())
+
+		require.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "abc123", result[0].ID)
+		assert.Equal(t, "def456", result[1].ID)
+		mockClient.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RemoveImages selects which images a down operation removes, as `docker compose down --rmi` does.
+type RemoveImages string
+
+const (
+	// RemoveImagesLocal removes only images built locally, i.e. images without
+	// a repo digest, which were never pulled from or pushed to a registry.
+	// This covers the vsc-* images built by the Dev Containers tooling.
+	RemoveImagesLocal RemoveImages = "local"
+	// RemoveImagesAll removes every image used by the containers.
+	RemoveImagesAll RemoveImages = "all"
+)
+
+// vscImagePrefix is the prefix of images built by the Dev Containers tooling.
+const vscImagePrefix = "vsc-"
+
+// PlanImages adds the images of the plan's containers to the plan.
+// Images built by the Dev Containers tooling come in layers, e.g. vsc-app-1a2b3c
+// with vsc-app-1a2b3c-features and vsc-app-1a2b3c-uid on top; the other layers of
+// a container's image are included too, after it so that they are removed last.
+// Images still used by containers outside the plan are skipped.
+func (c *ComposeOps) PlanImages(ctx context.Context, plan *Plan, mode RemoveImages) error {
+	images, err := c.client.ImageList(ctx, ImageListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list images: %w", err)
+	}
+
+	containers, err := c.client.ContainerList(ctx, ContainerListOptions{All: true})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	inPlan := make(map[string]bool, len(plan.Containers))
+	for _, container := range plan.Containers {
+		inPlan[container.ID] = true
+	}
+	inUse := make(map[string]bool)
+	for _, container := range containers {
+		if !inPlan[container.ID] {
+			inUse[container.ImageID] = true
+		}
+	}
+
+	var planned []ImageInfo
+	for _, img := range containerImages(images, plan.Containers) {
+		if inUse[img.ID] {
+			continue
+		}
+		if mode == RemoveImagesLocal && len(img.RepoDigests) > 0 {
+			continue
+		}
+		planned = append(planned, img)
+	}
+
+	plan.Images = planned
+	return nil
+}
+
+// containerImages returns the images of the given containers, each followed by
+// the other vsc-* layers it was built with, upper layers first.
+func containerImages(images []ImageInfo, containers []ContainerInfo) []ImageInfo {
+	byID := make(map[string]ImageInfo, len(images))
+	for _, img := range images {
+		byID[img.ID] = img
+	}
+
+	var result []ImageInfo
+	seen := make(map[string]bool)
+	add := func(img ImageInfo) {
+		if !seen[img.ID] {
+			seen[img.ID] = true
+			result = append(result, img)
+		}
+	}
+
+	for _, container := range containers {
+		img, ok := byID[container.ImageID]
+		if !ok {
+			continue
+		}
+		add(img)
+
+		for _, stem := range vscImageStems(img) {
+			var layers []ImageInfo
+			for _, other := range images {
+				if other.ID != img.ID && vscLayer(other, stem) >= 0 {
+					layers = append(layers, other)
+				}
+			}
+			// Remove the upper layers first, as an image cannot be removed while others build on it
+			sort.SliceStable(layers, func(i, j int) bool {
+				return vscLayer(layers[i], stem) > vscLayer(layers[j], stem)
+			})
+			for _, layer := range layers {
+				add(layer)
+			}
+		}
+	}
+
+	return result
+}
+
+// vscImageStems returns the repository names of an image's vsc-* tags,
+// without the -features and -uid suffixes of the derived layers.
+func vscImageStems(img ImageInfo) []string {
+	var stems []string
+	for _, tag := range img.RepoTags {
+		repo := imageRepository(tag)
+		if !strings.HasPrefix(repo, vscImagePrefix) {
+			continue
+		}
+		repo = strings.TrimSuffix(repo, "-uid")
+		repo = strings.TrimSuffix(repo, "-features")
+		stems = append(stems, repo)
+	}
+	return stems
+}
+
+// vscLayer returns how far up the image is in the layers built from stem:
+// 0 for the base image, 1 for -features, 2 for -uid (on top of either),
+// or -1 if the image is not built from stem.
+func vscLayer(img ImageInfo, stem string) int {
+	layer := -1
+	for _, tag := range img.RepoTags {
+		switch imageRepository(tag) {
+		case stem:
+			layer = max(layer, 0)
+		case stem + "-features":
+			layer = max(layer, 1)
+		case stem + "-uid", stem + "-features-uid":
+			layer = max(layer, 2)
+		}
+	}
+	return layer
+}
+
+// imageRepository returns the repository part of an image reference, without the tag.
+func imageRepository(ref string) string {
+	// A colon after the last slash separates the tag; one before it is a registry port
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
+}
+
+// Name returns the first tag of the image, or its short ID if it is untagged.
+func (i ImageInfo) Name() string {
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			return tag
+		}
+	}
+	id := strings.TrimPrefix(i.ID, "sha256:")
+	return id[:min(12, len(id))]
+}
+
+// UniqueSize returns the size of the layers only this image uses, or its
+// whole size if the daemon did not report the shared size.
+func (i ImageInfo) UniqueSize() int64 {
+	if i.SharedSize < 0 {
+		return i.Size
+	}
+	return i.Size - i.SharedSize
+}
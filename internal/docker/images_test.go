@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// imageNames returns the names of the images in order.
+func imageNames(images []ImageInfo) []string {
+	names := make([]string, len(images))
+	for i, img := range images {
+		names[i] = img.Name()
+	}
+	return names
+}
+
+func TestPlanImages(t *testing.T) {
+	base := ImageInfo{ID: "sha256:base", RepoTags: []string{"vsc-app-1a2b3c:latest"}}
+	features := ImageInfo{ID: "sha256:features", RepoTags: []string{"vsc-app-1a2b3c-features:latest"}}
+	uid := ImageInfo{ID: "sha256:uid", RepoTags: []string{"vsc-app-1a2b3c-features-uid:latest"}}
+	other := ImageInfo{ID: "sha256:other", RepoTags: []string{"vsc-other-4d5e6f:latest"}}
+	postgres := ImageInfo{ID: "sha256:postgres", RepoTags: []string{"postgres:16"}, RepoDigests: []string{"postgres@sha256:abc"}}
+	images := []ImageInfo{base, features, uid, other, postgres}
+
+	app := ContainerInfo{ID: "app1", ImageID: "sha256:uid"}
+	db := ContainerInfo{ID: "db1", ImageID: "sha256:postgres"}
+
+	t.Run("local removes locally built images and their layers, top layer first", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+		mockClient.On("ImageList", mock.Anything, ImageListOptions{}).Return(images, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true}).Return([]ContainerInfo{app, db}, nil)
+
+		plan := &Plan{Containers: []ContainerInfo{app, db}}
+		err := NewComposeOps(mockClient).PlanImages(context.Background(), plan, RemoveImagesLocal)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"vsc-app-1a2b3c-features-uid:latest",
+			"vsc-app-1a2b3c-features:latest",
+			"vsc-app-1a2b3c:latest",
+		}, imageNames(plan.Images))
+	})
+
+	t.Run("all also removes pulled images", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+		mockClient.On("ImageList", mock.Anything, ImageListOptions{}).Return(images, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true}).Return([]ContainerInfo{app, db}, nil)
+
+		plan := &Plan{Containers: []ContainerInfo{db}}
+		err := NewComposeOps(mockClient).PlanImages(context.Background(), plan, RemoveImagesAll)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"postgres:16"}, imageNames(plan.Images))
+	})
+
+	t.Run("skips images used by other containers", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+		otherApp := ContainerInfo{ID: "app2", ImageID: "sha256:base"}
+		mockClient.On("ImageList", mock.Anything, ImageListOptions{}).Return(images, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true}).Return([]ContainerInfo{app, otherApp, db}, nil)
+
+		plan := &Plan{Containers: []ContainerInfo{app}}
+		err := NewComposeOps(mockClient).PlanImages(context.Background(), plan, RemoveImagesLocal)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"vsc-app-1a2b3c-features-uid:latest",
+			"vsc-app-1a2b3c-features:latest",
+		}, imageNames(plan.Images))
+	})
+}
+
+func TestImageRepository(t *testing.T) {
+	assert.Equal(t, "vsc-app", imageRepository("vsc-app:latest"))
+	assert.Equal(t, "localhost:5000/app", imageRepository("localhost:5000/app:dev"))
+	assert.Equal(t, "localhost:5000/app", imageRepository("localhost:5000/app"))
+}
+
+func TestImageInfoName(t *testing.T) {
+	assert.Equal(t, "postgres:16", ImageInfo{ID: "sha256:abc", RepoTags: []string{"postgres:16"}}.Name())
+	assert.Equal(t, "0123456789ab", ImageInfo{ID: "sha256:0123456789abcdef", RepoTags: []string{"<none>:<none>"}}.Name())
+}
@@ -0,0 +1,143 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MatchStrategy describes how containers were matched to a devcontainer config.
+type MatchStrategy string
+
+const (
+	// MatchNone means no containers were found.
+	MatchNone MatchStrategy = ""
+	// MatchConfigFile matches the devcontainer.config_file label exactly.
+	MatchConfigFile MatchStrategy = "config_file label"
+	// MatchLocalFolder matches the devcontainer.local_folder label exactly.
+	MatchLocalFolder MatchStrategy = "local_folder label"
+	// MatchCanonicalConfigFile matches the config_file label after resolving symlinks.
+	MatchCanonicalConfigFile MatchStrategy = "canonicalized config_file label"
+	// MatchCanonicalLocalFolder matches the local_folder label after resolving symlinks.
+	MatchCanonicalLocalFolder MatchStrategy = "canonicalized local_folder label"
+)
+
+// MatchResult holds the containers matched to a devcontainer config and how they were found.
+type MatchResult struct {
+	Containers []ContainerInfo
+	Strategy   MatchStrategy
+}
+
+// FindDevcontainers finds the containers of an image-based devcontainer.
+// It tries, in order:
+//
+//  1. the config_file label
+//  2. the local_folder label
+//  3. the config_file label, comparing canonicalized paths
+//  4. the local_folder label, comparing canonicalized paths
+//
+// Canonicalization resolves symlinks (e.g. a symlinked home directory) and the
+// macOS /private prefix, so labels written with a different path form still match.
+// Folder matches skip containers whose config_file label points to a different
+// config that still exists, so sibling configs in the same folder are not mixed up.
+func (c *ContainerOps) FindDevcontainers(ctx context.Context, configPath, localFolder string) (*MatchResult, error) {
+	containers, err := c.FindDevcontainersByConfigPath(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) > 0 {
+		return &MatchResult{Containers: containers, Strategy: MatchConfigFile}, nil
+	}
+
+	containers, err = c.FindDevcontainersByFolder(ctx, localFolder)
+	if err != nil {
+		return nil, err
+	}
+	containers = excludeOtherConfigs(containers, configPath)
+	if len(containers) > 0 {
+		return &MatchResult{Containers: containers, Strategy: MatchLocalFolder}, nil
+	}
+
+	containers, err = c.findByCanonicalLabel(ctx, LabelConfigFile, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) > 0 {
+		return &MatchResult{Containers: containers, Strategy: MatchCanonicalConfigFile}, nil
+	}
+
+	containers, err = c.findByCanonicalLabel(ctx, LabelLocalFolder, localFolder)
+	if err != nil {
+		return nil, err
+	}
+	containers = excludeOtherConfigs(containers, configPath)
+	if len(containers) > 0 {
+		return &MatchResult{Containers: containers, Strategy: MatchCanonicalLocalFolder}, nil
+	}
+
+	return &MatchResult{Strategy: MatchNone}, nil
+}
+
+// findByCanonicalLabel finds containers whose label value refers to the same path as path.
+func (c *ContainerOps) findByCanonicalLabel(ctx context.Context, label, path string) ([]ContainerInfo, error) {
+	containers, err := c.client.ContainerList(ctx, ContainerListOptions{
+		All:         true,
+		LabelFilter: label,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers with label %s: %w", label, err)
+	}
+
+	want := canonicalPath(path)
+	var result []ContainerInfo
+	for _, container := range containers {
+		if canonicalPath(container.Labels[label]) == want {
+			result = append(result, container)
+		}
+	}
+
+	return result, nil
+}
+
+// excludeOtherConfigs drops containers that belong to a different devcontainer
+// config which still exists on disk.
+func excludeOtherConfigs(containers []ContainerInfo, configPath string) []ContainerInfo {
+	want := canonicalPath(configPath)
+	result := make([]ContainerInfo, 0, len(containers))
+
+	for _, container := range containers {
+		labelled := container.Labels[LabelConfigFile]
+		if labelled != "" && canonicalPath(labelled) != want {
+			if _, err := os.Stat(labelled); err == nil {
+				continue
+			}
+		}
+		result = append(result, container)
+	}
+
+	return result
+}
+
+// canonicalPath returns a canonical form of path for comparison.
+// Symlinks are resolved when the path exists, and the /private prefix macOS
+// adds to /var, /tmp and /etc is removed.
+func canonicalPath(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	for _, dir := range []string{"/var", "/tmp", "/etc"} {
+		if path == "/private"+dir || strings.HasPrefix(path, "/private"+dir+"/") {
+			return strings.TrimPrefix(path, "/private")
+		}
+	}
+
+	return path
+}
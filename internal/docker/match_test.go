@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDevcontainers(t *testing.T) {
+	configPath := "/home/user/myproject/.devcontainer/devcontainer.json"
+	folder := "/home/user/myproject"
+
+	t.Run("matches by config_file label first", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file=" + configPath}).
+			Return([]ContainerInfo{{ID: "abc123"}}, nil)
+
+		ops := NewContainerOps(mockClient)
+		result, err := ops.FindDevcontainers(context.Background(), configPath, folder)
+
+		require.NoError(t, err)
+		assert.Equal(t, MatchConfigFile, result.Strategy)
+		assert.Len(t, result.Containers, 1)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("falls back to local_folder label", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file=" + configPath}).
+			Return([]ContainerInfo{}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.local_folder=" + folder}).
+			Return([]ContainerInfo{{
+				ID: "moved123",
+				Labels: map[string]string{
+					"devcontainer.local_folder": folder,
+					"devcontainer.config_file":  "/home/user/myproject/.devcontainer/old/devcontainer.json",
+				},
+			}}, nil)
+
+		ops := NewContainerOps(mockClient)
+		result, err := ops.FindDevcontainers(context.Background(), configPath, folder)
+
+		require.NoError(t, err)
+		assert.Equal(t, MatchLocalFolder, result.Strategy)
+		require.Len(t, result.Containers, 1)
+		assert.Equal(t, "moved123", result.Containers[0].ID)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("folder match skips containers of other existing configs", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		otherConfig := filepath.Join(tmpDir, ".devcontainer", "other", "devcontainer.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(otherConfig), 0755))
+		require.NoError(t, os.WriteFile(otherConfig, []byte(`{}`), 0644))
+		ownConfig := filepath.Join(tmpDir, ".devcontainer", "devcontainer.json")
+
+		mockClient := new(MockContainerClient)
+
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file=" + ownConfig}).
+			Return([]ContainerInfo{}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.local_folder=" + tmpDir}).
+			Return([]ContainerInfo{{
+				ID:     "sibling123",
+				Labels: map[string]string{"devcontainer.local_folder": tmpDir, "devcontainer.config_file": otherConfig},
+			}}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file"}).
+			Return([]ContainerInfo{}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.local_folder"}).
+			Return([]ContainerInfo{}, nil)
+
+		ops := NewContainerOps(mockClient)
+		result, err := ops.FindDevcontainers(context.Background(), ownConfig, tmpDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, MatchNone, result.Strategy)
+		assert.Empty(t, result.Containers)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("matches symlinked config_file label", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		realDir := filepath.Join(tmpDir, "real")
+		require.NoError(t, os.MkdirAll(filepath.Join(realDir, ".devcontainer"), 0755))
+		realConfig := filepath.Join(realDir, ".devcontainer", "devcontainer.json")
+		require.NoError(t, os.WriteFile(realConfig, []byte(`{}`), 0644))
+		linkDir := filepath.Join(tmpDir, "link")
+		require.NoError(t, os.Symlink(realDir, linkDir))
+		linkConfig := filepath.Join(linkDir, ".devcontainer", "devcontainer.json")
+
+		mockClient := new(MockContainerClient)
+
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file=" + realConfig}).
+			Return([]ContainerInfo{}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.local_folder=" + realDir}).
+			Return([]ContainerInfo{}, nil)
+		mockClient.On("ContainerList", mock.Anything, ContainerListOptions{All: true, LabelFilter: "devcontainer.config_file"}).
+			Return([]ContainerInfo{
+				{ID: "linked123", Labels: map[string]string{"devcontainer.config_file": linkConfig}},
+				{ID: "other456", Labels: map[string]string{"devcontainer.config_file": "/elsewhere/devcontainer.json"}},
+			}, nil)
+
+		ops := NewContainerOps(mockClient)
+		result, err := ops.FindDevcontainers(context.Background(), realConfig, realDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, MatchCanonicalConfigFile, result.Strategy)
+		require.Len(t, result.Containers, 1)
+		assert.Equal(t, "linked123", result.Containers[0].ID)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestCanonicalPath(t *testing.T) {
+	assert.Equal(t, "/var/folders/x", canonicalPath("/private/var/folders/x"))
+	assert.Equal(t, "/tmp", canonicalPath("/private/tmp"))
+	assert.Equal(t, "/privateer/x", canonicalPath("/privateer/x/"))
+	assert.Equal(t, "", canonicalPath(""))
+}
@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"fmt"
+)
+
+// LoadServices sets the plan's service dependencies and stop options from the given
+// compose files. When no files are given, it uses the compose files recorded on
+// the plan's containers.
+func (p *Plan) LoadServices(composeFiles []string) error {
+	if len(composeFiles) == 0 {
+		for _, c := range p.Containers {
+			if files := composeConfigFiles(c); len(files) > 0 {
+				composeFiles = files
+				break
+			}
+		}
+	}
+	if len(composeFiles) == 0 {
+		return nil
+	}
+
+	project, err := LoadComposeProject(composeFiles)
+	if err != nil {
+		return fmt.Errorf("failed to load compose services: %w", err)
+	}
+	p.Dependencies = project.Dependencies()
+	p.StopOptions = project.StopOptions()
+	return nil
+}
+
+// StopOrder groups the plan's containers into batches to stop one after another,
+// in reverse dependency order as `docker compose down` does: a service is only
+// stopped once every service that depends on it has stopped.
+// Containers within a batch do not depend on each other and can be stopped concurrently.
+// Containers without a compose service label go in the first batch, and services
+// in a dependency cycle go in the last.
+func (p *Plan) StopOrder() [][]ContainerInfo {
+	if len(p.Containers) == 0 {
+		return nil
+	}
+
+	var services []string
+	byService := make(map[string][]ContainerInfo)
+	var unlabelled []ContainerInfo
+	for _, c := range p.Containers {
+		service := c.Labels[LabelComposeService]
+		if service == "" {
+			unlabelled = append(unlabelled, c)
+			continue
+		}
+		if _, ok := byService[service]; !ok {
+			services = append(services, service)
+		}
+		byService[service] = append(byService[service], c)
+	}
+
+	// Count, for each service, the services still running that depend on it
+	dependents := make(map[string]int)
+	for _, service := range services {
+		for _, dep := range p.Dependencies[service] {
+			if _, ok := byService[dep]; ok && dep != service {
+				dependents[dep]++
+			}
+		}
+	}
+
+	var order [][]ContainerInfo
+	batch := unlabelled
+	remaining := services
+	for len(remaining) > 0 {
+		var ready, blocked []string
+		for _, service := range remaining {
+			if dependents[service] == 0 {
+				ready = append(ready, service)
+			} else {
+				blocked = append(blocked, service)
+			}
+		}
+		if len(ready) == 0 {
+			// A dependency cycle; stop the rest together
+			ready, blocked = blocked, nil
+		}
+
+		for _, service := range ready {
+			batch = append(batch, byService[service]...)
+			for _, dep := range p.Dependencies[service] {
+				if _, ok := byService[dep]; ok && dep != service {
+					dependents[dep]--
+				}
+			}
+		}
+		order = append(order, batch)
+		batch = nil
+		remaining = blocked
+	}
+	if len(batch) > 0 {
+		order = append(order, batch)
+	}
+
+	return order
+}
@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serviceContainer(id, service string) ContainerInfo {
+	return ContainerInfo{ID: id, Labels: map[string]string{LabelComposeService: service}}
+}
+
+// batchIDs returns the container IDs of each batch.
+func batchIDs(order [][]ContainerInfo) [][]string {
+	ids := make([][]string, len(order))
+	for i, batch := range order {
+		for _, c := range batch {
+			ids[i] = append(ids[i], c.ID)
+		}
+	}
+	return ids
+}
+
+func TestStopOrder(t *testing.T) {
+	t.Run("stops dependents before their dependencies", func(t *testing.T) {
+		plan := &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("db1", "db"),
+				serviceContainer("app1", "app"),
+				serviceContainer("cache1", "cache"),
+				serviceContainer("proxy1", "proxy"),
+			},
+			Dependencies: map[string][]string{
+				"proxy": {"app"},
+				"app":   {"db", "cache"},
+			},
+		}
+
+		assert.Equal(t, [][]string{{"proxy1"}, {"app1"}, {"db1", "cache1"}}, batchIDs(plan.StopOrder()))
+	})
+
+	t.Run("stops independent services together", func(t *testing.T) {
+		plan := &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("db1", "db"),
+				serviceContainer("app1", "app"),
+				serviceContainer("worker1", "worker"),
+				serviceContainer("worker2", "worker"),
+			},
+			Dependencies: map[string][]string{
+				"app":    {"db"},
+				"worker": {"db"},
+			},
+		}
+
+		assert.Equal(t, [][]string{{"app1", "worker1", "worker2"}, {"db1"}}, batchIDs(plan.StopOrder()))
+	})
+
+	t.Run("stops everything at once without dependencies", func(t *testing.T) {
+		plan := &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("db1", "db"),
+				{ID: "plain1"},
+			},
+		}
+
+		assert.Equal(t, [][]string{{"plain1", "db1"}}, batchIDs(plan.StopOrder()))
+	})
+
+	t.Run("ignores dependencies on services without containers", func(t *testing.T) {
+		plan := &Plan{
+			Containers: []ContainerInfo{serviceContainer("app1", "app")},
+			Dependencies: map[string][]string{
+				"app": {"db"},
+			},
+		}
+
+		assert.Equal(t, [][]string{{"app1"}}, batchIDs(plan.StopOrder()))
+	})
+
+	t.Run("stops services in a dependency cycle last", func(t *testing.T) {
+		plan := &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("a1", "a"),
+				serviceContainer("b1", "b"),
+				serviceContainer("c1", "c"),
+			},
+			Dependencies: map[string][]string{
+				"a": {"b"},
+				"b": {"a"},
+				"c": {"a"},
+			},
+		}
+
+		assert.Equal(t, [][]string{{"c1"}, {"a1", "b1"}}, batchIDs(plan.StopOrder()))
+	})
+
+	t.Run("returns nothing for an empty plan", func(t *testing.T) {
+		assert.Empty(t, (&Plan{}).StopOrder())
+	})
+}
+
+func TestLoadServices(t *testing.T) {
+	compose := `services:
+  app:
+    depends_on:
+      - db
+  db:
+    stop_signal: SIGINT
+`
+
+	t.Run("reads the given compose files", func(t *testing.T) {
+		composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+		require.NoError(t, os.WriteFile(composeFile, []byte(compose), 0644))
+
+		plan := &Plan{}
+		require.NoError(t, plan.LoadServices([]string{composeFile}))
+		assert.Equal(t, []string{"db"}, plan.Dependencies["app"])
+		assert.Equal(t, "SIGINT", plan.StopOptions["db"].Signal)
+	})
+
+	t.Run("falls back to the compose files recorded on containers", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "docker-compose.yml"), []byte(compose), 0644))
+
+		plan := &Plan{Containers: []ContainerInfo{{
+			ID: "app1",
+			Labels: map[string]string{
+				LabelComposeConfigFiles: "docker-compose.yml",
+				LabelComposeWorkingDir:  tmpDir,
+			},
+		}}}
+		require.NoError(t, plan.LoadServices(nil))
+		assert.Equal(t, []string{"db"}, plan.Dependencies["app"])
+	})
+
+	t.Run("leaves dependencies empty without compose files", func(t *testing.T) {
+		plan := &Plan{Containers: []ContainerInfo{{ID: "app1"}}}
+		require.NoError(t, plan.LoadServices(nil))
+		assert.Nil(t, plan.Dependencies)
+	})
+}
@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultParallel is the number of containers acted on at once unless configured otherwise.
+const DefaultParallel = 4
+
+// forEachContainer calls fn for each container, running at most parallel calls at once.
+// It returns the error of each call in the order of containers.
+// Once ctx is cancelled no further calls are started, and the containers
+// that were skipped report ctx's error.
+func forEachContainer(ctx context.Context, parallel int, containers []ContainerInfo, fn func(context.Context, ContainerInfo) error) []error {
+	errs := make([]error, len(containers))
+	sem := make(chan struct{}, max(1, parallel))
+	var wg sync.WaitGroup
+
+	for i, container := range containers {
+		// Check first, as select picks randomly when both cases are ready
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, container)
+		}()
+	}
+
+	wg.Wait()
+	return errs
+}
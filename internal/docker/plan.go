@@ -0,0 +1,223 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Plan lists the resources a stop or down operation acts on.
+// Planning only reads from Docker; nothing is changed until the plan is applied.
+type Plan struct {
+	ProjectName      string
+	Containers       []ContainerInfo
+	RemoveContainers bool
+	Networks         []NetworkInfo
+	Volumes          []VolumeInfo
+	// BackupDir is the directory the volumes are archived to before they are removed, if set.
+	BackupDir string
+	Images    []ImageInfo
+	// Dependencies maps compose services to the services they depend on.
+	// Containers are stopped in reverse dependency order; see StopOrder.
+	Dependencies map[string][]string
+	// StopOptions maps compose services to how their containers are stopped.
+	StopOptions map[string]ContainerStopOptions
+	// Services lists the compose services the plan is restricted to, if any.
+	Services []string
+}
+
+// ReclaimsSpace returns true if the plan removes containers, volumes or images,
+// i.e. resources that take up disk space.
+func (p *Plan) ReclaimsSpace() bool {
+	return p.RemoveContainers || len(p.Volumes) > 0 || len(p.Images) > 0
+}
+
+// OnlyServices restricts the plan to the containers of the given compose services.
+// Networks, volumes and images are dropped, since the project's other services still use them.
+func (p *Plan) OnlyServices(services []string) {
+	var containers []ContainerInfo
+	for _, c := range p.Containers {
+		if slices.Contains(services, c.Labels[LabelComposeService]) {
+			containers = append(containers, c)
+		}
+	}
+
+	p.Services = services
+	p.Containers = containers
+	p.Networks = nil
+	p.Volumes = nil
+	p.Images = nil
+}
+
+// ResourceType identifies the kind of Docker resource an action applies to.
+type ResourceType string
+
+const (
+	ResourceContainer ResourceType = "container"
+	ResourceNetwork   ResourceType = "network"
+	ResourceVolume    ResourceType = "volume"
+	ResourceImage     ResourceType = "image"
+)
+
+// Action identifies what was done to a resource.
+type Action string
+
+const (
+	ActionStop    Action = "stop"
+	ActionStart   Action = "start"
+	ActionRemove  Action = "remove"
+	ActionBackup  Action = "backup"
+	ActionRestore Action = "restore"
+)
+
+// ActionResult records the outcome of one action on one resource.
+type ActionResult struct {
+	Type   ResourceType
+	ID     string
+	Name   string
+	Action Action
+	Err    error
+}
+
+// Apply stops the plan's containers and removes them if the plan says so.
+// Containers are stopped and removed in the batches given by StopOrder; the
+// containers in a batch are handled concurrently, see SetParallel.
+// Every action is attempted even if an earlier one fails; it returns the result
+// of each action and the failures joined into one error.
+// Networks and volumes are ignored; use ComposeOps.Apply for compose plans.
+func (c *ContainerOps) Apply(ctx context.Context, plan *Plan) ([]ActionResult, error) {
+	var results []ActionResult
+	var errs []error
+
+	order := plan.StopOrder()
+
+	for _, batch := range order {
+		for i, err := range forEachContainer(ctx, c.parallel, batch, c.stopper(plan)) {
+			container := batch[i]
+			results = append(results, ActionResult{
+				Type: ResourceContainer, ID: container.ID, Name: container.Name(), Action: ActionStop, Err: err,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop container %s: %w", container.ID, err))
+			}
+		}
+	}
+
+	if plan.RemoveContainers {
+		for _, batch := range order {
+			for i, err := range forEachContainer(ctx, c.parallel, batch, c.remove) {
+				container := batch[i]
+				results = append(results, ActionResult{
+					Type: ResourceContainer, ID: container.ID, Name: container.Name(), Action: ActionRemove, Err: err,
+				})
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove container %s: %w", container.ID, err))
+				}
+			}
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// PlanStop plans stopping all containers in a compose project.
+func (c *ComposeOps) PlanStop(ctx context.Context, projectName string) (*Plan, error) {
+	containers, err := c.FindComposeContainers(ctx, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find compose containers: %w", err)
+	}
+
+	return &Plan{
+		ProjectName: projectName,
+		Containers:  containers,
+	}, nil
+}
+
+// PlanDown plans stopping and removing containers and networks,
+// and optionally volumes, for a compose project.
+func (c *ComposeOps) PlanDown(ctx context.Context, projectName string, removeVolumes bool) (*Plan, error) {
+	plan, err := c.PlanStop(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	plan.RemoveContainers = true
+
+	plan.Networks, err = c.client.NetworkList(ctx, NetworkListOptions{
+		LabelFilter: fmt.Sprintf("%s=%s", LabelComposeProject, projectName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	if removeVolumes {
+		plan.Volumes, err = c.client.VolumeList(ctx, VolumeListOptions{
+			LabelFilter: fmt.Sprintf("%s=%s", LabelComposeProject, projectName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list volumes: %w", err)
+		}
+		plan.BackupDir = c.backupDir
+	}
+
+	return plan, nil
+}
+
+// Apply executes a compose plan: it stops and removes containers,
+// then removes networks, volumes and images. Every action is attempted even if an
+// earlier one fails, so one stuck container does not leave the rest of the
+// project behind. It returns the result of each action and the failures joined into one error.
+func (c *ComposeOps) Apply(ctx context.Context, plan *Plan) ([]ActionResult, error) {
+	containerOps := NewContainerOps(c.client)
+	containerOps.SetParallel(c.parallel)
+	containerOps.SetStopOptions(c.stopOpts)
+	results, err := containerOps.Apply(ctx, plan)
+	errs := []error{err}
+
+	for _, network := range plan.Networks {
+		err := c.client.NetworkRemove(ctx, network.ID)
+		results = append(results, ActionResult{
+			Type: ResourceNetwork, ID: network.ID, Name: network.Name, Action: ActionRemove, Err: err,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove network %s: %w", network.Name, err))
+		}
+	}
+
+	for _, volume := range plan.Volumes {
+		if plan.BackupDir != "" {
+			_, err := c.BackupVolume(ctx, volume, plan.BackupDir)
+			results = append(results, ActionResult{
+				Type: ResourceVolume, ID: volume.Name, Name: volume.Name, Action: ActionBackup, Err: err,
+			})
+			if err != nil {
+				// Never remove a volume that could not be backed up
+				errs = append(errs, fmt.Errorf("failed to back up volume %s, not removing it: %w", volume.Name, err))
+				continue
+			}
+		}
+
+		err := c.client.VolumeRemove(ctx, volume.Name, true)
+		results = append(results, ActionResult{
+			Type: ResourceVolume, ID: volume.Name, Name: volume.Name, Action: ActionRemove, Err: err,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove volume %s: %w", volume.Name, err))
+		}
+	}
+
+	// Images go last, once no container uses them. Removal is not forced: the
+	// daemon refuses to remove an image by ID that has tags the project may not
+	// have created, and that conflict is reported rather than dropping the tags.
+	for _, img := range plan.Images {
+		err := c.client.ImageRemove(ctx, img.ID, false)
+		results = append(results, ActionResult{
+			Type: ResourceImage, ID: img.ID, Name: img.Name(), Action: ActionRemove, Err: err,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove image %s: %w", img.Name(), err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
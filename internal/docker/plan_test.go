@@ -0,0 +1,373 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDown(t *testing.T) {
+	t.Run("collects resources without changing anything", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		containers := []ContainerInfo{{ID: "web123", Names: []string{"/myproject-web-1"}}}
+		networks := []NetworkInfo{{ID: "net123", Name: "myproject_default"}}
+		volumes := []VolumeInfo{{Name: "myproject_data"}}
+
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return(containers, nil)
+		mockClient.On("NetworkList", mock.Anything, mock.Anything).Return(networks, nil)
+		mockClient.On("VolumeList", mock.Anything, mock.Anything).Return(volumes, nil)
+
+		ops := NewComposeOps(mockClient)
+		plan, err := ops.PlanDown(context.Background(), "myproject", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, "myproject", plan.ProjectName)
+		assert.Equal(t, containers, plan.Containers)
+		assert.True(t, plan.RemoveContainers)
+		assert.Equal(t, networks, plan.Networks)
+		assert.Equal(t, volumes, plan.Volumes)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+		mockClient.AssertNotCalled(t, "ContainerRemove", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("does not list volumes unless requested", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{}, nil)
+		mockClient.On("NetworkList", mock.Anything, mock.Anything).Return([]NetworkInfo{}, nil)
+
+		ops := NewComposeOps(mockClient)
+		plan, err := ops.PlanDown(context.Background(), "myproject", false)
+
+		require.NoError(t, err)
+		assert.Empty(t, plan.Volumes)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("backs up the volumes it removes", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{}, nil)
+		mockClient.On("NetworkList", mock.Anything, mock.Anything).Return([]NetworkInfo{}, nil)
+		mockClient.On("VolumeList", mock.Anything, mock.Anything).Return([]VolumeInfo{{Name: "myproject_data"}}, nil)
+
+		ops := NewComposeOps(mockClient)
+		ops.SetBackupDir("/backups")
+
+		plan, err := ops.PlanDown(context.Background(), "myproject", true)
+		require.NoError(t, err)
+		assert.Equal(t, "/backups", plan.BackupDir)
+
+		plan, err = ops.PlanDown(context.Background(), "myproject", false)
+		require.NoError(t, err)
+		assert.Empty(t, plan.BackupDir)
+	})
+}
+
+func TestPlanStop(t *testing.T) {
+	t.Run("does not remove containers", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{{ID: "web123"}}, nil)
+
+		ops := NewComposeOps(mockClient)
+		plan, err := ops.PlanStop(context.Background(), "myproject")
+
+		require.NoError(t, err)
+		assert.False(t, plan.RemoveContainers)
+		assert.Len(t, plan.Containers, 1)
+		assert.Empty(t, plan.Networks)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestPlanReclaimsSpace(t *testing.T) {
+	assert.False(t, (&Plan{Containers: []ContainerInfo{{ID: "web123"}}}).ReclaimsSpace())
+	assert.False(t, (&Plan{Networks: []NetworkInfo{{ID: "net123"}}}).ReclaimsSpace())
+	assert.True(t, (&Plan{RemoveContainers: true}).ReclaimsSpace())
+	assert.True(t, (&Plan{Volumes: []VolumeInfo{{Name: "myproject_data"}}}).ReclaimsSpace())
+	assert.True(t, (&Plan{Images: []ImageInfo{{ID: "sha256:web"}}}).ReclaimsSpace())
+}
+
+func TestPlanOnlyServices(t *testing.T) {
+	plan := &Plan{
+		ProjectName: "myproject",
+		Containers: []ContainerInfo{
+			{ID: "app1", Labels: map[string]string{LabelComposeService: "app"}},
+			{ID: "db1", Labels: map[string]string{LabelComposeService: "db"}},
+		},
+		RemoveContainers: true,
+		Networks:         []NetworkInfo{{ID: "net123", Name: "myproject_default"}},
+		Volumes:          []VolumeInfo{{Name: "myproject_data"}},
+	}
+
+	plan.OnlyServices([]string{"app"})
+
+	assert.Equal(t, []string{"app"}, plan.Services)
+	require.Len(t, plan.Containers, 1)
+	assert.Equal(t, "app1", plan.Containers[0].ID)
+	assert.True(t, plan.RemoveContainers)
+	assert.Empty(t, plan.Networks)
+	assert.Empty(t, plan.Volumes)
+}
+
+func TestContainerOpsApply(t *testing.T) {
+	t.Run("stops and removes planned containers", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		mockClient.On("ContainerStop", mock.Anything, "container1", mock.Anything).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, "container1", true).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Containers:       []ContainerInfo{{ID: "container1", Names: []string{"/dev1"}}},
+			RemoveContainers: true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []ActionResult{
+			{Type: ResourceContainer, ID: "container1", Name: "dev1", Action: ActionStop},
+			{Type: ResourceContainer, ID: "container1", Name: "dev1", Action: ActionRemove},
+		}, results)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("only stops when removal is not planned", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		mockClient.On("ContainerStop", mock.Anything, "container1", mock.Anything).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Containers: []ContainerInfo{{ID: "container1"}},
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "ContainerRemove", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stops dependents before their dependencies", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		var stopped []string
+		record := func(args mock.Arguments) { stopped = append(stopped, args.String(1)) }
+		mockClient.On("ContainerStop", mock.Anything, mock.Anything, mock.Anything).Run(record).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, mock.Anything, true).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Containers: []ContainerInfo{
+				{ID: "db1", Labels: map[string]string{LabelComposeService: "db"}},
+				{ID: "app1", Labels: map[string]string{LabelComposeService: "app"}},
+			},
+			RemoveContainers: true,
+			Dependencies:     map[string][]string{"app": {"db"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app1", "db1"}, stopped)
+		require.Len(t, results, 4)
+		assert.Equal(t, "app1", results[0].ID)
+		assert.Equal(t, "db1", results[1].ID)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("stops containers with per-service stop options", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		jvmTimeout := 60
+		mockClient.On("ContainerStop", mock.Anything, "jvm1", ContainerStopOptions{Timeout: &jvmTimeout}).Return(nil)
+		mockClient.On("ContainerStop", mock.Anything, "db1", ContainerStopOptions{Signal: "SIGINT"}).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		_, err := ops.Apply(context.Background(), &Plan{
+			Containers: []ContainerInfo{
+				{ID: "jvm1", Labels: map[string]string{LabelComposeService: "jvm"}},
+				{ID: "db1", Labels: map[string]string{LabelComposeService: "db"}},
+			},
+			StopOptions: map[string]ContainerStopOptions{
+				"jvm": {Timeout: &jvmTimeout},
+				"db":  {Signal: "SIGINT"},
+			},
+		})
+
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("stop options set on ops take precedence over per-service options", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		jvmTimeout := 60
+		killNow := 0
+		mockClient.On("ContainerStop", mock.Anything, "jvm1", ContainerStopOptions{Timeout: &killNow, Signal: "SIGINT"}).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		ops.SetStopOptions(ContainerStopOptions{Timeout: &killNow})
+		_, err := ops.Apply(context.Background(), &Plan{
+			Containers: []ContainerInfo{
+				{ID: "jvm1", Labels: map[string]string{LabelComposeService: "jvm"}},
+			},
+			StopOptions: map[string]ContainerStopOptions{
+				"jvm": {Timeout: &jvmTimeout, Signal: "SIGINT"},
+			},
+		})
+
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("continues after a container fails to stop", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		stopErr := errors.New("timeout")
+		mockClient.On("ContainerStop", mock.Anything, "container1", mock.Anything).Return(stopErr)
+		mockClient.On("ContainerStop", mock.Anything, "container2", mock.Anything).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Containers: []ContainerInfo{{ID: "container1"}, {ID: "container2"}},
+		})
+
+		require.ErrorIs(t, err, stopErr)
+		assert.Contains(t, err.Error(), "container1")
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, results[0].Err, stopErr)
+		assert.NoError(t, results[1].Err)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestComposeOpsApply(t *testing.T) {
+	t.Run("records results for every resource", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("ContainerStop", mock.Anything, "web123", mock.Anything).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, "web123", true).Return(nil)
+		mockClient.On("NetworkRemove", mock.Anything, "net123").Return(nil)
+		mockClient.On("VolumeRemove", mock.Anything, "myproject_data", true).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			ProjectName:      "myproject",
+			Containers:       []ContainerInfo{{ID: "web123", Names: []string{"/myproject-web-1"}}},
+			RemoveContainers: true,
+			Networks:         []NetworkInfo{{ID: "net123", Name: "myproject_default"}},
+			Volumes:          []VolumeInfo{{Name: "myproject_data"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []ActionResult{
+			{Type: ResourceContainer, ID: "web123", Name: "myproject-web-1", Action: ActionStop},
+			{Type: ResourceContainer, ID: "web123", Name: "myproject-web-1", Action: ActionRemove},
+			{Type: ResourceNetwork, ID: "net123", Name: "myproject_default", Action: ActionRemove},
+			{Type: ResourceVolume, ID: "myproject_data", Name: "myproject_data", Action: ActionRemove},
+		}, results)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("keeps volumes that could not be backed up", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+		mockClient.On("VolumeArchive", mock.Anything, "myproject_data").Return(nil, errors.New("no space left"))
+		mockClient.On("VolumeArchive", mock.Anything, "myproject_cache").
+			Return(io.NopCloser(bytes.NewReader(volumeTar(t, nil))), nil)
+		mockClient.On("VolumeRemove", mock.Anything, "myproject_cache", true).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Volumes:   []VolumeInfo{{Name: "myproject_data"}, {Name: "myproject_cache"}},
+			BackupDir: t.TempDir(),
+		})
+
+		require.ErrorContains(t, err, "not removing it")
+		mockClient.AssertNotCalled(t, "VolumeRemove", mock.Anything, "myproject_data", mock.Anything)
+		assert.Len(t, results, 3)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("removes images after containers", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		var calls []string
+		mockClient.On("ContainerStop", mock.Anything, "web123", mock.Anything).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, "web123", true).Run(func(mock.Arguments) {
+			calls = append(calls, "remove container")
+		}).Return(nil)
+		mockClient.On("ImageRemove", mock.Anything, "sha256:web", false).Run(func(mock.Arguments) {
+			calls = append(calls, "remove image")
+		}).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Containers:       []ContainerInfo{{ID: "web123", ImageID: "sha256:web"}},
+			RemoveContainers: true,
+			Images:           []ImageInfo{{ID: "sha256:web", RepoTags: []string{"vsc-web-1a2b3c:latest"}}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"remove container", "remove image"}, calls)
+		assert.Equal(t, ActionResult{
+			Type: ResourceImage, ID: "sha256:web", Name: "vsc-web-1a2b3c:latest", Action: ActionRemove,
+		}, results[len(results)-1])
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("reports images the daemon refuses to remove without force", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		conflict := errors.New("conflict: unable to delete 1a2b3c (must be forced) - image is referenced in multiple repositories")
+		mockClient.On("ContainerStop", mock.Anything, "web123", mock.Anything).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, "web123", true).Return(nil)
+		mockClient.On("ImageRemove", mock.Anything, "sha256:web", false).Return(conflict)
+
+		ops := NewComposeOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			Containers:       []ContainerInfo{{ID: "web123", ImageID: "sha256:web"}},
+			RemoveContainers: true,
+			Images:           []ImageInfo{{ID: "sha256:web", RepoTags: []string{"vsc-web-1a2b3c:latest", "myorg/web:dev"}}},
+		})
+
+		require.ErrorIs(t, err, conflict)
+		assert.ErrorContains(t, err, "failed to remove image vsc-web-1a2b3c:latest")
+		assert.Equal(t, conflict, results[len(results)-1].Err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("cleans up networks and volumes after failures", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		stopErr := errors.New("timeout")
+		removeErr := errors.New("network has active endpoints")
+		mockClient.On("ContainerStop", mock.Anything, "web123", mock.Anything).Return(stopErr)
+		mockClient.On("ContainerStop", mock.Anything, "db123", mock.Anything).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, "web123", true).Return(nil)
+		mockClient.On("ContainerRemove", mock.Anything, "db123", true).Return(nil)
+		mockClient.On("NetworkRemove", mock.Anything, "net123").Return(removeErr)
+		mockClient.On("VolumeRemove", mock.Anything, "myproject_data", true).Return(nil)
+
+		ops := NewComposeOps(mockClient)
+		results, err := ops.Apply(context.Background(), &Plan{
+			ProjectName:      "myproject",
+			Containers:       []ContainerInfo{{ID: "web123"}, {ID: "db123"}},
+			RemoveContainers: true,
+			Networks:         []NetworkInfo{{ID: "net123", Name: "myproject_default"}},
+			Volumes:          []VolumeInfo{{Name: "myproject_data"}},
+		})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, stopErr)
+		assert.ErrorIs(t, err, removeErr)
+		assert.Len(t, results, 6)
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Project groups the containers that belong to one devcontainer or compose project.
+type Project struct {
+	Name           string
+	ComposeProject string
+	LocalFolder    string
+	ConfigFile     string
+	ComposeFiles   []string
+	Containers     []ContainerInfo
+}
+
+// IsCompose returns true if the project is managed by Docker Compose.
+func (p *Project) IsCompose() bool {
+	return p.ComposeProject != ""
+}
+
+// IsDevcontainer returns true if any container in the project was created
+// by the Dev Containers tooling.
+func (p *Project) IsDevcontainer() bool {
+	return p.LocalFolder != "" || p.ConfigFile != ""
+}
+
+// MissingFiles returns the devcontainer config and compose files recorded in
+// the project's labels that no longer exist on disk, e.g. because the
+// repository was deleted or moved. Files that cannot be checked for other
+// reasons are assumed to exist. Not every missing file makes the project
+// orphaned, see IsOrphaned.
+func (p *Project) MissingFiles() []string {
+	var missing []string
+	files := append([]string{p.ConfigFile}, p.ComposeFiles...)
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		if isMissing(f) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// IsOrphaned returns true if the project's workspace is gone, so the
+// devcontainer can no longer be reopened. This is decided by the devcontainer
+// config file, as the compose files may include overrides that the Dev
+// Containers tooling generates in temporary or editor storage and cleans up on
+// its own. Without a config file label, every compose file has to be gone.
+func (p *Project) IsOrphaned() bool {
+	if p.ConfigFile != "" {
+		return isMissing(p.ConfigFile)
+	}
+	if len(p.ComposeFiles) == 0 {
+		return false
+	}
+	for _, f := range p.ComposeFiles {
+		if !isMissing(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// isMissing returns true if path does not exist. Paths that cannot be checked
+// for other reasons are assumed to exist.
+func isMissing(path string) bool {
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// GroupProjects groups containers by their compose project, or by their
+// devcontainer config file for image-based devcontainers.
+// Projects are sorted by name.
+func GroupProjects(containers []ContainerInfo) []*Project {
+	byKey := make(map[string]*Project)
+	var projects []*Project
+
+	for _, c := range containers {
+		composeProject := c.Labels[LabelComposeProject]
+		localFolder := c.Labels[LabelLocalFolder]
+		configFile := c.Labels[LabelConfigFile]
+
+		// Compose projects are keyed by project name, image-based
+		// devcontainers by config file (or folder when the config label is missing)
+		key := "compose:" + composeProject
+		if composeProject == "" {
+			key = "devcontainer:" + configFile
+			if configFile == "" {
+				key = "devcontainer:" + localFolder
+			}
+		}
+
+		p, ok := byKey[key]
+		if !ok {
+			p = &Project{ComposeProject: composeProject}
+			byKey[key] = p
+			projects = append(projects, p)
+		}
+
+		// Only the devcontainer's primary container carries these labels in compose projects
+		if p.LocalFolder == "" {
+			p.LocalFolder = localFolder
+		}
+		if p.ConfigFile == "" {
+			p.ConfigFile = configFile
+		}
+		if len(p.ComposeFiles) == 0 {
+			p.ComposeFiles = composeConfigFiles(c)
+		}
+		p.Containers = append(p.Containers, c)
+	}
+
+	for _, p := range projects {
+		switch {
+		case p.ComposeProject != "":
+			p.Name = p.ComposeProject
+		case p.LocalFolder != "":
+			p.Name = filepath.Base(p.LocalFolder)
+		default:
+			p.Name = filepath.Base(filepath.Dir(p.ConfigFile))
+		}
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+
+	return projects
+}
@@ -0,0 +1,142 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupProjects(t *testing.T) {
+	t.Run("groups compose containers by project and image containers by config", func(t *testing.T) {
+		containers := []ContainerInfo{
+			{
+				ID: "web123",
+				Labels: map[string]string{
+					"com.docker.compose.project": "myproject",
+					"com.docker.compose.service": "web",
+					"devcontainer.local_folder":  "/home/user/myproject",
+					"devcontainer.config_file":   "/home/user/myproject/.devcontainer/devcontainer.json",
+				},
+			},
+			{
+				ID: "db456",
+				Labels: map[string]string{
+					"com.docker.compose.project": "myproject",
+					"com.docker.compose.service": "db",
+				},
+			},
+			{
+				ID: "abc789",
+				Labels: map[string]string{
+					"devcontainer.local_folder": "/home/user/another",
+					"devcontainer.config_file":  "/home/user/another/.devcontainer/devcontainer.json",
+				},
+			},
+		}
+
+		projects := GroupProjects(containers)
+		require.Len(t, projects, 2)
+
+		assert.Equal(t, "another", projects[0].Name)
+		assert.False(t, projects[0].IsCompose())
+		assert.True(t, projects[0].IsDevcontainer())
+		assert.Len(t, projects[0].Containers, 1)
+
+		assert.Equal(t, "myproject", projects[1].Name)
+		assert.True(t, projects[1].IsCompose())
+		assert.Equal(t, "/home/user/myproject", projects[1].LocalFolder)
+		assert.Equal(t, "/home/user/myproject/.devcontainer/devcontainer.json", projects[1].ConfigFile)
+		assert.Len(t, projects[1].Containers, 2)
+	})
+
+	t.Run("compose project without devcontainer labels", func(t *testing.T) {
+		containers := []ContainerInfo{
+			{ID: "web123", Labels: map[string]string{"com.docker.compose.project": "plain"}},
+		}
+
+		projects := GroupProjects(containers)
+		require.Len(t, projects, 1)
+		assert.True(t, projects[0].IsCompose())
+		assert.False(t, projects[0].IsDevcontainer())
+	})
+
+	t.Run("records the compose files of the project", func(t *testing.T) {
+		containers := []ContainerInfo{
+			{ID: "web123", Labels: map[string]string{
+				"com.docker.compose.project":              "myproject",
+				"com.docker.compose.project.config_files": "docker-compose.yml,.devcontainer/docker-compose.yml",
+				"com.docker.compose.project.working_dir":  "/home/user/myproject",
+			}},
+		}
+
+		projects := GroupProjects(containers)
+		require.Len(t, projects, 1)
+		assert.Equal(t, []string{
+			"/home/user/myproject/docker-compose.yml",
+			"/home/user/myproject/.devcontainer/docker-compose.yml",
+		}, projects[0].ComposeFiles)
+	})
+
+	t.Run("handles empty list", func(t *testing.T) {
+		assert.Empty(t, GroupProjects(nil))
+	})
+}
+
+func TestProject_MissingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, ".devcontainer", "devcontainer.json")
+	composeFile := filepath.Join(tmpDir, "docker-compose.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0755))
+	require.NoError(t, os.WriteFile(configFile, []byte(`{}`), 0644))
+	require.NoError(t, os.WriteFile(composeFile, []byte(`services: {}`), 0644))
+
+	t.Run("all files exist", func(t *testing.T) {
+		p := &Project{ConfigFile: configFile, ComposeFiles: []string{composeFile}}
+		assert.Empty(t, p.MissingFiles())
+		assert.False(t, p.IsOrphaned())
+	})
+
+	t.Run("config file removed", func(t *testing.T) {
+		gone := filepath.Join(tmpDir, "moved", ".devcontainer", "devcontainer.json")
+		p := &Project{ConfigFile: gone, ComposeFiles: []string{composeFile}}
+		assert.Equal(t, []string{gone}, p.MissingFiles())
+		assert.True(t, p.IsOrphaned())
+	})
+
+	t.Run("compose file removed", func(t *testing.T) {
+		gone := filepath.Join(tmpDir, "docker-compose.override.yml")
+		p := &Project{ConfigFile: configFile, ComposeFiles: []string{composeFile, gone}}
+		assert.Equal(t, []string{gone}, p.MissingFiles())
+	})
+
+	t.Run("generated override removed", func(t *testing.T) {
+		// The Dev Containers tooling records its generated override next to the
+		// user's compose files and may clean it up while the workspace still exists
+		gone := filepath.Join(tmpDir, "globalStorage", "ms-vscode-remote.remote-containers",
+			"data", "docker-compose", "docker-compose.devcontainer.build-1700000000000.yml")
+		p := &Project{ConfigFile: configFile, ComposeFiles: []string{composeFile, gone}}
+		assert.Equal(t, []string{gone}, p.MissingFiles())
+		assert.False(t, p.IsOrphaned())
+	})
+
+	t.Run("compose files removed without config file label", func(t *testing.T) {
+		gone := filepath.Join(tmpDir, "moved", "docker-compose.yml")
+		p := &Project{LocalFolder: tmpDir, ComposeFiles: []string{gone}}
+		assert.True(t, p.IsOrphaned())
+	})
+
+	t.Run("only some compose files removed without config file label", func(t *testing.T) {
+		gone := filepath.Join(tmpDir, "docker-compose.override.yml")
+		p := &Project{LocalFolder: tmpDir, ComposeFiles: []string{composeFile, gone}}
+		assert.Equal(t, []string{gone}, p.MissingFiles())
+		assert.False(t, p.IsOrphaned())
+	})
+
+	t.Run("no files recorded", func(t *testing.T) {
+		p := &Project{LocalFolder: "/home/user/project"}
+		assert.False(t, p.IsOrphaned())
+	})
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// StartOrder groups the plan's containers into batches to start one after another,
+// in dependency order as `docker compose up` does: a service is only started once
+// every service it depends on has started. It is the reverse of StopOrder.
+func (p *Plan) StartOrder() [][]ContainerInfo {
+	order := p.StopOrder()
+	slices.Reverse(order)
+	return order
+}
+
+// MissingServices returns the compose services defined in the plan's compose
+// files that have no container, e.g. because they were removed. It is empty
+// unless the services were loaded with LoadServices.
+func (p *Plan) MissingServices() []string {
+	found := make(map[string]bool)
+	for _, c := range p.Containers {
+		found[c.Labels[LabelComposeService]] = true
+	}
+
+	var missing []string
+	for service := range p.Dependencies {
+		if !found[service] {
+			missing = append(missing, service)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Start starts the plan's containers in the batches given by StartOrder; the
+// containers in a batch are started concurrently, see SetParallel.
+// Containers are never created, so services without a container stay missing.
+// Every container is attempted even if an earlier one fails; it returns the result
+// of each action and the failures joined into one error.
+func (c *ContainerOps) Start(ctx context.Context, plan *Plan) ([]ActionResult, error) {
+	var results []ActionResult
+	var errs []error
+
+	for _, batch := range plan.StartOrder() {
+		for i, err := range forEachContainer(ctx, c.parallel, batch, c.start) {
+			container := batch[i]
+			results = append(results, ActionResult{
+				Type: ResourceContainer, ID: container.ID, Name: container.Name(), Action: ActionStart, Err: err,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to start container %s: %w", container.ID, err))
+			}
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// Restart stops the plan's containers in StopOrder, then starts them again in StartOrder.
+// It returns the results of both steps.
+func (c *ContainerOps) Restart(ctx context.Context, plan *Plan) ([]ActionResult, error) {
+	stopPlan := *plan
+	stopPlan.RemoveContainers = false
+
+	stopResults, stopErr := c.Apply(ctx, &stopPlan)
+	startResults, startErr := c.Start(ctx, plan)
+
+	return append(stopResults, startResults...), errors.Join(stopErr, startErr)
+}
+
+// start starts a single container.
+func (c *ContainerOps) start(ctx context.Context, container ContainerInfo) error {
+	return c.client.ContainerStart(ctx, container.ID)
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartOrder(t *testing.T) {
+	plan := &Plan{
+		Containers: []ContainerInfo{
+			serviceContainer("db1", "db"),
+			serviceContainer("app1", "app"),
+			serviceContainer("proxy1", "proxy"),
+		},
+		Dependencies: map[string][]string{
+			"proxy": {"app"},
+			"app":   {"db"},
+		},
+	}
+
+	assert.Equal(t, [][]string{{"db1"}, {"app1"}, {"proxy1"}}, batchIDs(plan.StartOrder()))
+}
+
+func TestMissingServices(t *testing.T) {
+	t.Run("lists services without a container", func(t *testing.T) {
+		plan := &Plan{
+			Containers: []ContainerInfo{serviceContainer("app1", "app")},
+			Dependencies: map[string][]string{
+				"app":   {"db"},
+				"db":    nil,
+				"cache": nil,
+			},
+		}
+
+		assert.Equal(t, []string{"cache", "db"}, plan.MissingServices())
+	})
+
+	t.Run("is empty without compose services", func(t *testing.T) {
+		plan := &Plan{Containers: []ContainerInfo{{ID: "abc123"}}}
+		assert.Empty(t, plan.MissingServices())
+	})
+}
+
+func TestContainerOpsStart(t *testing.T) {
+	t.Run("starts dependencies before their dependents", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		var started []string
+		record := func(args mock.Arguments) { started = append(started, args.String(1)) }
+		mockClient.On("ContainerStart", mock.Anything, mock.Anything).Run(record).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Start(context.Background(), &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("app1", "app"),
+				serviceContainer("db1", "db"),
+			},
+			Dependencies: map[string][]string{"app": {"db"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db1", "app1"}, started)
+		require.Len(t, results, 2)
+		assert.Equal(t, ActionStart, results[0].Action)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("continues after a container fails to start", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		startErr := errors.New("port is already allocated")
+		mockClient.On("ContainerStart", mock.Anything, "db1").Return(startErr)
+		mockClient.On("ContainerStart", mock.Anything, "app1").Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Start(context.Background(), &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("app1", "app"),
+				serviceContainer("db1", "db"),
+			},
+			Dependencies: map[string][]string{"app": {"db"}},
+		})
+
+		require.ErrorIs(t, err, startErr)
+		assert.Len(t, results, 2)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestContainerOpsRestart(t *testing.T) {
+	t.Run("stops in reverse dependency order, then starts in dependency order", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+
+		var calls []string
+		mockClient.On("ContainerStop", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			calls = append(calls, "stop "+args.String(1))
+		}).Return(nil)
+		mockClient.On("ContainerStart", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			calls = append(calls, "start "+args.String(1))
+		}).Return(nil)
+
+		ops := NewContainerOps(mockClient)
+		results, err := ops.Restart(context.Background(), &Plan{
+			Containers: []ContainerInfo{
+				serviceContainer("db1", "db"),
+				serviceContainer("app1", "app"),
+			},
+			RemoveContainers: true,
+			Dependencies:     map[string][]string{"app": {"db"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"stop app1", "stop db1", "start db1", "start app1"}, calls)
+		assert.Len(t, results, 4)
+		mockClient.AssertNotCalled(t, "ContainerRemove", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,192 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+)
+
+// DiskUsageOptions selects which object types a disk usage query includes.
+// Computing sizes can be slow on the daemon, so only request what is needed.
+type DiskUsageOptions struct {
+	Containers bool
+	Images     bool
+	Volumes    bool
+	BuildCache bool
+}
+
+// DiskUsage represents disk usage reported by the Docker daemon.
+type DiskUsage struct {
+	Containers []ContainerInfo
+	Images     []ImageInfo
+	Volumes    []VolumeInfo
+	// BuildCacheSize is the size of the build cache not shared with images.
+	BuildCacheSize int64
+}
+
+// ProjectUsage is the disk space used by one project, in bytes.
+type ProjectUsage struct {
+	// Containers is the size of the containers' writable layers.
+	Containers int64
+	// Volumes is the size of the compose project's volumes.
+	Volumes int64
+	// Images is the unique size of the containers' images and their vsc-* layers.
+	// Layers shared with other images are not counted.
+	Images int64
+	// SharedImages is the unique size of the images that containers outside the
+	// project use as well, e.g. a database image of several compose projects.
+	SharedImages int64
+}
+
+// Total returns the space used by the project in all. Shared images are not
+// included, as they would be counted once for every project that uses them.
+func (u ProjectUsage) Total() int64 {
+	return u.Containers + u.Volumes + u.Images
+}
+
+// ProjectUsage returns the disk space used by the given project.
+// Build cache cannot be attributed to a project and is not included.
+func (u *DiskUsage) ProjectUsage(p *Project) ProjectUsage {
+	var usage ProjectUsage
+
+	inProject := make(map[string]bool, len(p.Containers))
+	for _, c := range p.Containers {
+		inProject[c.ID] = true
+	}
+	for _, c := range u.Containers {
+		if inProject[c.ID] {
+			usage.Containers += c.SizeRw
+		}
+	}
+
+	if p.IsCompose() {
+		for _, v := range u.Volumes {
+			if v.Labels[LabelComposeProject] == p.ComposeProject && v.Size > 0 {
+				usage.Volumes += v.Size
+			}
+		}
+	}
+
+	own, shared := u.projectImages(p)
+	for _, img := range own {
+		usage.Images += img.UniqueSize()
+	}
+	for _, img := range shared {
+		usage.SharedImages += img.UniqueSize()
+	}
+
+	return usage
+}
+
+// SharedImageSize returns the unique size of the images that the given projects
+// share with containers outside them, counting each image once.
+func (u *DiskUsage) SharedImageSize(projects []*Project) int64 {
+	var total int64
+	counted := make(map[string]bool)
+	for _, p := range projects {
+		_, shared := u.projectImages(p)
+		for _, img := range shared {
+			if !counted[img.ID] {
+				counted[img.ID] = true
+				total += img.UniqueSize()
+			}
+		}
+	}
+	return total
+}
+
+// projectImages returns the images of the project's containers, including their
+// vsc-* layers, split into those only the project uses and those that other
+// containers on the daemon use as well.
+func (u *DiskUsage) projectImages(p *Project) (own, shared []ImageInfo) {
+	inProject := make(map[string]bool, len(p.Containers))
+	for _, c := range p.Containers {
+		inProject[c.ID] = true
+	}
+	var others []ContainerInfo
+	for _, c := range u.Containers {
+		if !inProject[c.ID] {
+			others = append(others, c)
+		}
+	}
+	usedElsewhere := make(map[string]bool)
+	for _, img := range containerImages(u.Images, others) {
+		usedElsewhere[img.ID] = true
+	}
+
+	for _, img := range containerImages(u.Images, p.Containers) {
+		if usedElsewhere[img.ID] {
+			shared = append(shared, img)
+		} else {
+			own = append(own, img)
+		}
+	}
+	return own, shared
+}
+
+// Reclaimed returns the disk space freed by the given actions: the sizes of
+// the containers, volumes and images they removed successfully.
+func (u *DiskUsage) Reclaimed(results []ActionResult) int64 {
+	removed := make(map[ResourceType]map[string]bool)
+	for _, r := range results {
+		if r.Action != ActionRemove || r.Err != nil {
+			continue
+		}
+		if removed[r.Type] == nil {
+			removed[r.Type] = make(map[string]bool)
+		}
+		removed[r.Type][r.ID] = true
+	}
+
+	var total int64
+	for _, c := range u.Containers {
+		if removed[ResourceContainer][c.ID] {
+			total += c.SizeRw
+		}
+	}
+	for _, v := range u.Volumes {
+		if removed[ResourceVolume][v.Name] && v.Size > 0 {
+			total += v.Size
+		}
+	}
+	for _, img := range u.Images {
+		if removed[ResourceImage][img.ID] {
+			total += img.UniqueSize()
+		}
+	}
+	return total
+}
+
+// DiskUsage returns the daemon's disk usage for the object types selected in options.
+func (c *ComposeOps) DiskUsage(ctx context.Context, options DiskUsageOptions) (*DiskUsage, error) {
+	usage, err := c.client.DiskUsage(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	return usage, nil
+}
+
+// AddVolumeSizes fills in the Size of each volume from the daemon's disk usage data.
+// Volumes the daemon reports no size for keep a Size of -1.
+func (c *ComposeOps) AddVolumeSizes(ctx context.Context, volumes []VolumeInfo) error {
+	for i := range volumes {
+		volumes[i].Size = -1
+	}
+
+	usage, err := c.DiskUsage(ctx, DiskUsageOptions{Volumes: true})
+	if err != nil {
+		return err
+	}
+
+	sizes := make(map[string]int64, len(usage.Volumes))
+	for _, v := range usage.Volumes {
+		sizes[v.Name] = v.Size
+	}
+
+	for i := range volumes {
+		if size, ok := sizes[volumes[i].Name]; ok {
+			volumes[i].Size = size
+		}
+	}
+
+	return nil
+}
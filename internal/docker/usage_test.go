@@ -0,0 +1,146 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddVolumeSizes(t *testing.T) {
+	t.Run("fills in sizes from disk usage", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("DiskUsage", mock.Anything, DiskUsageOptions{Volumes: true}).Return(&DiskUsage{
+			Volumes: []VolumeInfo{
+				{Name: "myproject_data", Size: 2048},
+				{Name: "other_data", Size: 1},
+			},
+		}, nil)
+
+		volumes := []VolumeInfo{{Name: "myproject_data"}, {Name: "myproject_cache"}}
+
+		ops := NewComposeOps(mockClient)
+		err := ops.AddVolumeSizes(context.Background(), volumes)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2048), volumes[0].Size)
+		assert.Equal(t, int64(-1), volumes[1].Size)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("marks sizes unknown on error", func(t *testing.T) {
+		mockClient := new(MockComposeClient)
+
+		mockClient.On("DiskUsage", mock.Anything, mock.Anything).Return(nil, errors.New("daemon error"))
+
+		volumes := []VolumeInfo{{Name: "myproject_data"}}
+
+		ops := NewComposeOps(mockClient)
+		err := ops.AddVolumeSizes(context.Background(), volumes)
+
+		assert.Error(t, err)
+		assert.Equal(t, int64(-1), volumes[0].Size)
+	})
+}
+
+func TestDiskUsage_ProjectUsage(t *testing.T) {
+	usage := &DiskUsage{
+		Containers: []ContainerInfo{
+			{ID: "web123", SizeRw: 100},
+			{ID: "db456", SizeRw: 20},
+			{ID: "other789", SizeRw: 5000},
+		},
+		Volumes: []VolumeInfo{
+			{Name: "myproject_data", Labels: map[string]string{LabelComposeProject: "myproject"}, Size: 3000},
+			{Name: "myproject_cache", Labels: map[string]string{LabelComposeProject: "myproject"}, Size: -1},
+			{Name: "other_data", Labels: map[string]string{LabelComposeProject: "other"}, Size: 7000},
+		},
+		Images: []ImageInfo{
+			{ID: "sha256:web", RepoTags: []string{"vsc-myproject-1a2b3c-uid:latest"}, Size: 900, SharedSize: 800},
+			{ID: "sha256:base", RepoTags: []string{"vsc-myproject-1a2b3c:latest"}, Size: 800, SharedSize: 500},
+			{ID: "sha256:postgres", RepoTags: []string{"postgres:16"}, Size: 400, SharedSize: -1},
+		},
+	}
+
+	project := &Project{
+		ComposeProject: "myproject",
+		Containers: []ContainerInfo{
+			{ID: "web123", ImageID: "sha256:web"},
+			{ID: "db456", ImageID: "sha256:postgres"},
+		},
+	}
+
+	u := usage.ProjectUsage(project)
+	assert.Equal(t, ProjectUsage{Containers: 120, Volumes: 3000, Images: 100 + 300 + 400}, u)
+	assert.Equal(t, int64(3920), u.Total())
+}
+
+func TestDiskUsage_SharedImages(t *testing.T) {
+	usage := &DiskUsage{
+		Containers: []ContainerInfo{
+			{ID: "web1", ImageID: "sha256:web1", SizeRw: 10},
+			{ID: "db1", ImageID: "sha256:postgres", SizeRw: 20},
+			{ID: "web2", ImageID: "sha256:web2", SizeRw: 30},
+			{ID: "db2", ImageID: "sha256:postgres", SizeRw: 40},
+		},
+		Images: []ImageInfo{
+			{ID: "sha256:web1", RepoTags: []string{"vsc-one-1a2b3c:latest"}, Size: 100, SharedSize: -1},
+			{ID: "sha256:web2", RepoTags: []string{"vsc-two-4d5e6f:latest"}, Size: 200, SharedSize: -1},
+			{ID: "sha256:postgres", RepoTags: []string{"postgres:16"}, Size: 400, SharedSize: -1},
+		},
+	}
+
+	one := &Project{
+		ComposeProject: "one",
+		Containers: []ContainerInfo{
+			{ID: "web1", ImageID: "sha256:web1"},
+			{ID: "db1", ImageID: "sha256:postgres"},
+		},
+	}
+	two := &Project{
+		ComposeProject: "two",
+		Containers: []ContainerInfo{
+			{ID: "web2", ImageID: "sha256:web2"},
+			{ID: "db2", ImageID: "sha256:postgres"},
+		},
+	}
+
+	t.Run("reports images used by other projects separately", func(t *testing.T) {
+		assert.Equal(t, ProjectUsage{Containers: 30, Images: 100, SharedImages: 400}, usage.ProjectUsage(one))
+		assert.Equal(t, ProjectUsage{Containers: 70, Images: 200, SharedImages: 400}, usage.ProjectUsage(two))
+		assert.Equal(t, int64(130), usage.ProjectUsage(one).Total())
+	})
+
+	t.Run("counts each shared image once", func(t *testing.T) {
+		assert.Equal(t, int64(400), usage.SharedImageSize([]*Project{one, two}))
+	})
+
+	t.Run("counts an image as the project's own when nothing else uses it", func(t *testing.T) {
+		alone := &DiskUsage{Containers: usage.Containers[:2], Images: usage.Images}
+		assert.Equal(t, ProjectUsage{Containers: 30, Images: 500}, alone.ProjectUsage(one))
+		assert.Zero(t, alone.SharedImageSize([]*Project{one}))
+	})
+}
+
+func TestDiskUsage_Reclaimed(t *testing.T) {
+	usage := &DiskUsage{
+		Containers: []ContainerInfo{{ID: "web123", SizeRw: 100}, {ID: "db456", SizeRw: 20}},
+		Volumes:    []VolumeInfo{{Name: "myproject_data", Size: 3000}},
+		Images:     []ImageInfo{{ID: "sha256:web", Size: 900, SharedSize: 800}},
+	}
+
+	results := []ActionResult{
+		{Type: ResourceContainer, ID: "web123", Action: ActionStop},
+		{Type: ResourceContainer, ID: "web123", Action: ActionRemove},
+		{Type: ResourceContainer, ID: "db456", Action: ActionRemove, Err: errors.New("in use")},
+		{Type: ResourceNetwork, ID: "net1", Action: ActionRemove},
+		{Type: ResourceVolume, ID: "myproject_data", Action: ActionRemove},
+		{Type: ResourceImage, ID: "sha256:web", Action: ActionRemove},
+	}
+
+	assert.Equal(t, int64(100+3000+100), usage.Reclaimed(results))
+}
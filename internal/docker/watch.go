@@ -0,0 +1,293 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for WatchOptions.
+const (
+	DefaultIdleTimeout  = 30 * time.Minute
+	DefaultCPUThreshold = 1.0
+	DefaultInterval     = time.Minute
+)
+
+// ContainerEvent is a container event from the Docker daemon, such as
+// "start", "attach" or "exec_start: bash".
+type ContainerEvent struct {
+	ID     string
+	Action string
+}
+
+// WatchClient extends ComposeClient with the events and statistics Watcher samples.
+type WatchClient interface {
+	ComposeClient
+	// ContainerEvents streams container events until ctx is done or the stream fails.
+	ContainerEvents(ctx context.Context) (<-chan ContainerEvent, <-chan error)
+	// ContainerCPUPercent samples a container's CPU usage, as a percentage of one CPU.
+	ContainerCPUPercent(ctx context.Context, containerID string) (float64, error)
+	// ContainerExecSessions returns the number of exec sessions running in a container.
+	ContainerExecSessions(ctx context.Context, containerID string) (int, error)
+}
+
+// WatchOptions configures when Watcher considers a devcontainer idle.
+type WatchOptions struct {
+	// IdleTimeout is how long a devcontainer has to be idle before it is stopped.
+	IdleTimeout time.Duration
+	// CPUThreshold is the CPU usage, in percent of one CPU, below which a container is idle.
+	CPUThreshold float64
+	// Interval is how often containers are sampled.
+	Interval time.Duration
+}
+
+// containerActivity tracks when a container was last seen active.
+type containerActivity struct {
+	lastActive time.Time
+	// attached counts clients attached to the container, from attach and detach events.
+	attached int
+}
+
+// Watcher stops devcontainers that have been idle for too long.
+// A container is idle while its CPU usage is below the threshold and it has
+// no exec sessions (such as the VS Code server) or attached clients.
+// A devcontainer is stopped once all of its running containers have been idle
+// for the idle timeout.
+type Watcher struct {
+	client     WatchClient
+	ops        *ComposeOps
+	opts       WatchOptions
+	logf       func(format string, args ...any)
+	now        func() time.Time
+	containers map[string]*containerActivity
+}
+
+// NewWatcher creates a Watcher that stops idle devcontainers with ops.
+// Options that are not set take their defaults.
+func NewWatcher(client WatchClient, ops *ComposeOps, opts WatchOptions) *Watcher {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if opts.CPUThreshold <= 0 {
+		opts.CPUThreshold = DefaultCPUThreshold
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &Watcher{
+		client:     client,
+		ops:        ops,
+		opts:       opts,
+		logf:       func(string, ...any) {},
+		now:        time.Now,
+		containers: make(map[string]*containerActivity),
+	}
+}
+
+// SetLogger sets the function every decision is logged with.
+func (w *Watcher) SetLogger(logf func(format string, args ...any)) {
+	w.logf = logf
+}
+
+// Run watches devcontainers until ctx is done, sampling them every interval
+// and reacting to container events in between.
+// It returns nil when ctx is done, or an error if the event stream fails.
+func (w *Watcher) Run(ctx context.Context) error {
+	events, errs := w.client.ContainerEvents(ctx)
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	if err := w.Sample(ctx); err != nil {
+		w.logf("Sampling failed: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			w.HandleEvent(ev)
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("container event stream failed: %w", err)
+		case <-ticker.C:
+			if err := w.Sample(ctx); err != nil {
+				w.logf("Sampling failed: %v", err)
+			}
+		}
+	}
+}
+
+// HandleEvent records activity from a container event. Events catch activity
+// between samples, such as a short `docker exec`, and attached clients,
+// which cannot be sampled.
+func (w *Watcher) HandleEvent(ev ContainerEvent) {
+	act, ok := w.containers[ev.ID]
+	if !ok {
+		// Containers are tracked once they are sampled
+		return
+	}
+
+	// Exec actions carry the command, e.g. "exec_start: bash"
+	action, _, _ := strings.Cut(ev.Action, ":")
+	switch action {
+	case "start", "restart", "unpause", "exec_start":
+		act.lastActive = w.now()
+	case "attach":
+		act.attached++
+		act.lastActive = w.now()
+	case "detach":
+		act.attached = max(0, act.attached-1)
+		act.lastActive = w.now()
+	case "die", "destroy":
+		delete(w.containers, ev.ID)
+	default:
+		return
+	}
+	w.logf("%s: %s", ContainerInfo{ID: ev.ID}.ShortID(), ev.Action)
+}
+
+// containerSample is the activity of a container at one sample.
+type containerSample struct {
+	cpu   float64
+	execs int
+	err   error
+}
+
+// Sample checks the activity of every running devcontainer and stops those
+// that have been idle for the idle timeout.
+func (w *Watcher) Sample(ctx context.Context) error {
+	running, err := w.listRunning(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Stop tracking containers that are no longer running
+	seen := make(map[string]bool, len(running))
+	for _, c := range running {
+		seen[c.ID] = true
+	}
+	for id := range w.containers {
+		if !seen[id] {
+			delete(w.containers, id)
+		}
+	}
+
+	// Stats take a moment per container, so sample them concurrently
+	samples := make(map[string]containerSample, len(running))
+	var mu sync.Mutex
+	forEachContainer(ctx, w.ops.parallel, running, func(ctx context.Context, c ContainerInfo) error {
+		var s containerSample
+		s.cpu, s.err = w.client.ContainerCPUPercent(ctx, c.ID)
+		if s.err == nil {
+			s.execs, s.err = w.client.ContainerExecSessions(ctx, c.ID)
+		}
+		mu.Lock()
+		samples[c.ID] = s
+		mu.Unlock()
+		return nil
+	})
+
+	now := w.now()
+	for _, p := range GroupProjects(running) {
+		if !p.IsDevcontainer() {
+			continue
+		}
+
+		var lastActive time.Time
+		var reasons []string
+		for _, c := range p.Containers {
+			act, ok := w.containers[c.ID]
+			if !ok {
+				// Newly seen containers get a full idle timeout
+				act = &containerActivity{lastActive: now}
+				w.containers[c.ID] = act
+				w.logf("%s: watching container %s", p.Name, c.Name())
+			}
+
+			s := samples[c.ID]
+			reason := ""
+			switch {
+			case s.err != nil:
+				// Assume activity rather than stop a container that could not be checked
+				reason = fmt.Sprintf("%s could not be sampled: %v", c.Name(), s.err)
+			case s.execs > 0:
+				reason = fmt.Sprintf("%s has %d exec session(s)", c.Name(), s.execs)
+			case act.attached > 0:
+				reason = fmt.Sprintf("%s has %d attached client(s)", c.Name(), act.attached)
+			case s.cpu >= w.opts.CPUThreshold:
+				reason = fmt.Sprintf("%s uses %.1f%% CPU", c.Name(), s.cpu)
+			}
+			if reason != "" {
+				act.lastActive = now
+				reasons = append(reasons, reason)
+			}
+			if act.lastActive.After(lastActive) {
+				lastActive = act.lastActive
+			}
+		}
+
+		if len(reasons) > 0 {
+			w.logf("%s: active (%s)", p.Name, strings.Join(reasons, ", "))
+			continue
+		}
+
+		idle := now.Sub(lastActive)
+		if idle < w.opts.IdleTimeout {
+			w.logf("%s: idle for %s of %s", p.Name, idle.Round(time.Second), w.opts.IdleTimeout)
+			continue
+		}
+
+		w.logf("%s: idle for %s, stopping", p.Name, idle.Round(time.Second))
+		if err := w.stop(ctx, p); err != nil {
+			w.logf("%s: failed to stop: %v", p.Name, err)
+			continue
+		}
+		w.logf("%s: stopped", p.Name)
+		for _, c := range p.Containers {
+			delete(w.containers, c.ID)
+		}
+	}
+
+	return nil
+}
+
+// listRunning lists the running containers with devcontainer or compose labels.
+func (w *Watcher) listRunning(ctx context.Context) ([]ContainerInfo, error) {
+	containers, err := NewContainerOps(w.client).ListDevcontainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var running []ContainerInfo
+	for _, c := range containers {
+		if c.State == "running" {
+			running = append(running, c)
+		}
+	}
+	return running, nil
+}
+
+// stop stops a devcontainer: every service of a compose project, in reverse
+// dependency order, or the containers of an image-based devcontainer.
+func (w *Watcher) stop(ctx context.Context, p *Project) error {
+	plan := &Plan{Containers: p.Containers}
+	if p.IsCompose() {
+		var err error
+		plan, err = w.ops.PlanStop(ctx, p.ComposeProject)
+		if err != nil {
+			return err
+		}
+		if err := plan.LoadServices(nil); err != nil {
+			w.logf("%s: %v", p.Name, err)
+		}
+	}
+
+	_, err := w.ops.Apply(ctx, plan)
+	return err
+}
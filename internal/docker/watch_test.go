@@ -0,0 +1,202 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWatchClient extends MockComposeClient with events and statistics
+type MockWatchClient struct {
+	MockComposeClient
+}
+
+func (m *MockWatchClient) ContainerEvents(ctx context.Context) (<-chan ContainerEvent, <-chan error) {
+	args := m.Called(ctx)
+	return args.Get(0).(<-chan ContainerEvent), args.Get(1).(<-chan error)
+}
+
+func (m *MockWatchClient) ContainerCPUPercent(ctx context.Context, containerID string) (float64, error) {
+	args := m.Called(ctx, containerID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockWatchClient) ContainerExecSessions(ctx context.Context, containerID string) (int, error) {
+	args := m.Called(ctx, containerID)
+	return args.Int(0), args.Error(1)
+}
+
+// fakeClock is a clock for Watcher that only moves when advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestWatcher returns a Watcher with a 30 minute idle timeout and a 5% CPU
+// threshold, on a fake clock.
+func newTestWatcher(client *MockWatchClient) (*Watcher, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	w := NewWatcher(client, NewComposeOps(client), WatchOptions{IdleTimeout: 30 * time.Minute, CPUThreshold: 5})
+	w.now = clock.Now
+	return w, clock
+}
+
+var watchedContainer = ContainerInfo{
+	ID:    "app123",
+	Names: []string{"/app"},
+	State: "running",
+	Labels: map[string]string{
+		"devcontainer.local_folder": "/home/user/app",
+		"devcontainer.config_file":  "/home/user/app/.devcontainer/devcontainer.json",
+	},
+}
+
+func TestWatcher_Sample(t *testing.T) {
+	t.Run("stops a devcontainer idle for the idle timeout", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{watchedContainer}, nil)
+		mockClient.On("ContainerCPUPercent", mock.Anything, "app123").Return(0.5, nil)
+		mockClient.On("ContainerExecSessions", mock.Anything, "app123").Return(0, nil)
+		mockClient.On("ContainerStop", mock.Anything, "app123", mock.Anything).Return(nil)
+
+		w, clock := newTestWatcher(mockClient)
+
+		require.NoError(t, w.Sample(context.Background()))
+		clock.Advance(29 * time.Minute)
+		require.NoError(t, w.Sample(context.Background()))
+		mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+
+		clock.Advance(time.Minute)
+		require.NoError(t, w.Sample(context.Background()))
+		mockClient.AssertCalled(t, "ContainerStop", mock.Anything, "app123", mock.Anything)
+	})
+
+	t.Run("keeps a devcontainer with an exec session running", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{watchedContainer}, nil)
+		mockClient.On("ContainerCPUPercent", mock.Anything, "app123").Return(0.0, nil)
+		mockClient.On("ContainerExecSessions", mock.Anything, "app123").Return(1, nil)
+
+		w, clock := newTestWatcher(mockClient)
+
+		require.NoError(t, w.Sample(context.Background()))
+		clock.Advance(time.Hour)
+		require.NoError(t, w.Sample(context.Background()))
+		mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CPU above the threshold resets the idle time", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{watchedContainer}, nil)
+		mockClient.On("ContainerExecSessions", mock.Anything, "app123").Return(0, nil)
+		mockClient.On("ContainerCPUPercent", mock.Anything, "app123").Return(50.0, nil).Twice()
+		mockClient.On("ContainerCPUPercent", mock.Anything, "app123").Return(0.0, nil)
+
+		w, clock := newTestWatcher(mockClient)
+
+		require.NoError(t, w.Sample(context.Background()))
+		clock.Advance(20 * time.Minute)
+		require.NoError(t, w.Sample(context.Background()))
+		clock.Advance(20 * time.Minute)
+		require.NoError(t, w.Sample(context.Background()))
+		mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("treats containers that cannot be sampled as active", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{watchedContainer}, nil)
+		mockClient.On("ContainerCPUPercent", mock.Anything, "app123").Return(0.0, errors.New("stats unavailable"))
+
+		w, clock := newTestWatcher(mockClient)
+
+		require.NoError(t, w.Sample(context.Background()))
+		clock.Advance(time.Hour)
+		require.NoError(t, w.Sample(context.Background()))
+		mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ignores compose projects not created by devcontainer", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		plain := ContainerInfo{ID: "web123", State: "running", Labels: map[string]string{"com.docker.compose.project": "plain"}}
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{plain}, nil)
+		mockClient.On("ContainerCPUPercent", mock.Anything, "web123").Return(0.0, nil)
+		mockClient.On("ContainerExecSessions", mock.Anything, "web123").Return(0, nil)
+
+		w, clock := newTestWatcher(mockClient)
+
+		require.NoError(t, w.Sample(context.Background()))
+		clock.Advance(time.Hour)
+		require.NoError(t, w.Sample(context.Background()))
+		mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWatcher_HandleEvent(t *testing.T) {
+	mockClient := new(MockWatchClient)
+	mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{watchedContainer}, nil)
+	mockClient.On("ContainerCPUPercent", mock.Anything, "app123").Return(0.0, nil)
+	mockClient.On("ContainerExecSessions", mock.Anything, "app123").Return(0, nil)
+	mockClient.On("ContainerStop", mock.Anything, "app123", mock.Anything).Return(nil)
+
+	w, clock := newTestWatcher(mockClient)
+	require.NoError(t, w.Sample(context.Background()))
+
+	// An attached client keeps the container active until it detaches
+	w.HandleEvent(ContainerEvent{ID: "app123", Action: "attach"})
+	clock.Advance(time.Hour)
+	require.NoError(t, w.Sample(context.Background()))
+	mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+
+	w.HandleEvent(ContainerEvent{ID: "app123", Action: "detach"})
+
+	// A short exec between samples counts as activity
+	clock.Advance(20 * time.Minute)
+	w.HandleEvent(ContainerEvent{ID: "app123", Action: "exec_start: bash"})
+	clock.Advance(20 * time.Minute)
+	require.NoError(t, w.Sample(context.Background()))
+	mockClient.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+
+	clock.Advance(10 * time.Minute)
+	require.NoError(t, w.Sample(context.Background()))
+	mockClient.AssertCalled(t, "ContainerStop", mock.Anything, "app123", mock.Anything)
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Run("returns when the context is done", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		events := make(chan ContainerEvent)
+		errs := make(chan error)
+		mockClient.On("ContainerEvents", mock.Anything).Return((<-chan ContainerEvent)(events), (<-chan error)(errs))
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		w, _ := newTestWatcher(mockClient)
+		assert.NoError(t, w.Run(ctx))
+	})
+
+	t.Run("returns the event stream error", func(t *testing.T) {
+		mockClient := new(MockWatchClient)
+		events := make(chan ContainerEvent)
+		errs := make(chan error, 1)
+		errs <- errors.New("connection reset")
+		mockClient.On("ContainerEvents", mock.Anything).Return((<-chan ContainerEvent)(events), (<-chan error)(errs))
+		mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]ContainerInfo{}, nil)
+
+		w, _ := newTestWatcher(mockClient)
+		assert.ErrorContains(t, w.Run(context.Background()), "connection reset")
+	})
+}
@@ -0,0 +1,293 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dev-shimada/dcstop/internal/devcontainer"
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	FormatHuman = "human"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// Resource statuses.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusPlanned = "planned"
+)
+
+// Report is the machine-readable result of a dcstop run.
+type Report struct {
+	DryRun   bool       `json:"dryRun" yaml:"dryRun"`
+	Projects []*Project `json:"projects" yaml:"projects"`
+	// BuildCache is the size of the daemon's build cache in bytes, reported by du.
+	// It cannot be attributed to a project.
+	BuildCache int64 `json:"buildCache,omitempty" yaml:"buildCache,omitempty"`
+	// SharedImages is the size of the images shared between projects, counted once,
+	// reported by du.
+	SharedImages int64   `json:"sharedImages,omitempty" yaml:"sharedImages,omitempty"`
+	Summary      Summary `json:"summary" yaml:"summary"`
+}
+
+// Summary counts the actions in a report by status.
+type Summary struct {
+	OK      int `json:"ok" yaml:"ok"`
+	Failed  int `json:"failed" yaml:"failed"`
+	Planned int `json:"planned" yaml:"planned"`
+	// FailedProjects counts projects with an error, including errors
+	// that happened before any action was attempted.
+	FailedProjects int `json:"failedProjects" yaml:"failedProjects"`
+	// Reclaimed is the disk space freed across all projects, in bytes,
+	// or -1 if it is unknown for any of them.
+	Reclaimed int64 `json:"reclaimed,omitempty" yaml:"reclaimed,omitempty"`
+}
+
+// Project describes one devcontainer or compose project and what was done to it.
+type Project struct {
+	Name           string  `json:"name" yaml:"name"`
+	ComposeProject string  `json:"composeProject,omitempty" yaml:"composeProject,omitempty"`
+	LocalFolder    string  `json:"localFolder,omitempty" yaml:"localFolder,omitempty"`
+	Config         *Config `json:"config,omitempty" yaml:"config,omitempty"`
+	Match          string  `json:"match,omitempty" yaml:"match,omitempty"`
+	// Services lists the compose services acted on, when not the whole project.
+	Services   []string    `json:"services,omitempty" yaml:"services,omitempty"`
+	Containers []Container `json:"containers" yaml:"containers"`
+	Resources  []Resource  `json:"resources" yaml:"resources"`
+	// MissingFiles lists the config and compose files recorded on the
+	// containers that no longer exist.
+	MissingFiles []string `json:"missingFiles,omitempty" yaml:"missingFiles,omitempty"`
+	// Usage is the disk space used by the project, reported by du.
+	Usage *Usage `json:"usage,omitempty" yaml:"usage,omitempty"`
+	// Reclaimed is the disk space freed by removing the project's resources, in bytes,
+	// or -1 if the disk usage could not be read.
+	Reclaimed int64 `json:"reclaimed,omitempty" yaml:"reclaimed,omitempty"`
+	// MissingServices lists compose services that have no container to start.
+	MissingServices []string `json:"missingServices,omitempty" yaml:"missingServices,omitempty"`
+	Error           string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Config describes a devcontainer config.
+type Config struct {
+	Path           string   `json:"path" yaml:"path"`
+	Kind           string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Layout         string   `json:"layout,omitempty" yaml:"layout,omitempty"`
+	ComposeFiles   []string `json:"composeFiles,omitempty" yaml:"composeFiles,omitempty"`
+	Service        string   `json:"service,omitempty" yaml:"service,omitempty"`
+	RunServices    []string `json:"runServices,omitempty" yaml:"runServices,omitempty"`
+	ShutdownAction string   `json:"shutdownAction,omitempty" yaml:"shutdownAction,omitempty"`
+}
+
+// Container describes a container found for a project.
+type Container struct {
+	ID     string `json:"id" yaml:"id"`
+	Name   string `json:"name" yaml:"name"`
+	State  string `json:"state" yaml:"state"`
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// Usage describes the disk space used by a project, in bytes.
+type Usage struct {
+	Containers int64 `json:"containers" yaml:"containers"`
+	Volumes    int64 `json:"volumes" yaml:"volumes"`
+	Images     int64 `json:"images" yaml:"images"`
+	// SharedImages is not included in Total, see docker.ProjectUsage.
+	SharedImages int64 `json:"sharedImages" yaml:"sharedImages"`
+	Total        int64 `json:"total" yaml:"total"`
+}
+
+// Resource describes an action on a container, network or volume and its outcome.
+type Resource struct {
+	Type   string `json:"type" yaml:"type"`
+	ID     string `json:"id" yaml:"id"`
+	Name   string `json:"name" yaml:"name"`
+	Action string `json:"action" yaml:"action"`
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Tally counts the actions in the report by status.
+func (r *Report) Tally() Summary {
+	var s Summary
+	reclaimedKnown := true
+	for _, p := range r.Projects {
+		if p.Error != "" {
+			s.FailedProjects++
+		}
+		if p.Reclaimed < 0 {
+			reclaimedKnown = false
+		} else {
+			s.Reclaimed += p.Reclaimed
+		}
+		for _, res := range p.Resources {
+			switch res.Status {
+			case StatusOK:
+				s.OK++
+			case StatusFailed:
+				s.Failed++
+			case StatusPlanned:
+				s.Planned++
+			}
+		}
+	}
+	if !reclaimedKnown {
+		s.Reclaimed = -1
+	}
+	return s
+}
+
+// PartialFailure returns true if some actions failed while others succeeded.
+func (s Summary) PartialFailure() bool {
+	return s.OK > 0 && (s.Failed > 0 || s.FailedProjects > 0)
+}
+
+// ValidateFormat returns an error if format is not a supported output format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatHuman, FormatJSON, FormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (supported: %s, %s, %s)", format, FormatHuman, FormatJSON, FormatYAML)
+	}
+}
+
+// Write writes the report to w in the given structured format.
+func Write(w io.Writer, format string, r *Report) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// NewConfig describes a parsed devcontainer config.
+func NewConfig(cfg *devcontainer.Config) *Config {
+	return &Config{
+		Path:           cfg.ConfigPath,
+		Kind:           string(cfg.Kind()),
+		Layout:         string(cfg.Layout),
+		ComposeFiles:   cfg.GetComposeFiles(),
+		Service:        cfg.Service,
+		RunServices:    cfg.RunServices,
+		ShutdownAction: string(cfg.GetShutdownAction()),
+	}
+}
+
+// NewProject describes a project found on the daemon by its labels.
+func NewProject(p *docker.Project) *Project {
+	project := &Project{
+		Name:           p.Name,
+		ComposeProject: p.ComposeProject,
+		LocalFolder:    p.LocalFolder,
+		Containers:     Containers(p.Containers),
+		Resources:      []Resource{},
+	}
+	if p.ConfigFile != "" {
+		project.Config = &Config{Path: p.ConfigFile}
+	}
+	return project
+}
+
+// NewUsage describes the disk space used by a project.
+func NewUsage(u docker.ProjectUsage) *Usage {
+	return &Usage{
+		Containers:   u.Containers,
+		Volumes:      u.Volumes,
+		Images:       u.Images,
+		SharedImages: u.SharedImages,
+		Total:        u.Total(),
+	}
+}
+
+// Containers describes the given containers.
+func Containers(containers []docker.ContainerInfo) []Container {
+	result := make([]Container, len(containers))
+	for i, c := range containers {
+		result[i] = Container{
+			ID:     c.ID,
+			Name:   c.Name(),
+			State:  c.State,
+			Status: c.Status,
+		}
+	}
+	return result
+}
+
+// Results describes the outcome of applied actions.
+func Results(results []docker.ActionResult) []Resource {
+	resources := make([]Resource, len(results))
+	for i, r := range results {
+		resources[i] = Resource{
+			Type:   string(r.Type),
+			ID:     r.ID,
+			Name:   r.Name,
+			Action: string(r.Action),
+			Status: StatusOK,
+		}
+		if r.Err != nil {
+			resources[i].Status = StatusFailed
+			resources[i].Error = r.Err.Error()
+		}
+	}
+	return resources
+}
+
+// Planned describes the actions a plan would take, in the order they would be taken,
+// without applying them.
+func Planned(plan *docker.Plan) []Resource {
+	resources := []Resource{}
+	planned := func(t docker.ResourceType, id, name string, action docker.Action) {
+		resources = append(resources, Resource{
+			Type:   string(t),
+			ID:     id,
+			Name:   name,
+			Action: string(action),
+			Status: StatusPlanned,
+		})
+	}
+
+	order := plan.StopOrder()
+	for _, batch := range order {
+		for _, c := range batch {
+			planned(docker.ResourceContainer, c.ID, c.Name(), docker.ActionStop)
+		}
+	}
+	if plan.RemoveContainers {
+		for _, batch := range order {
+			for _, c := range batch {
+				planned(docker.ResourceContainer, c.ID, c.Name(), docker.ActionRemove)
+			}
+		}
+	}
+	for _, n := range plan.Networks {
+		planned(docker.ResourceNetwork, n.ID, n.Name, docker.ActionRemove)
+	}
+	for _, v := range plan.Volumes {
+		if plan.BackupDir != "" {
+			planned(docker.ResourceVolume, v.Name, v.Name, docker.ActionBackup)
+		}
+		planned(docker.ResourceVolume, v.Name, v.Name, docker.ActionRemove)
+	}
+	for _, img := range plan.Images {
+		planned(docker.ResourceImage, img.ID, img.Name(), docker.ActionRemove)
+	}
+
+	return resources
+}
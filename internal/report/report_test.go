@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dev-shimada/dcstop/internal/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, ValidateFormat(FormatHuman))
+	assert.NoError(t, ValidateFormat(FormatJSON))
+	assert.NoError(t, ValidateFormat(FormatYAML))
+	assert.Error(t, ValidateFormat("xml"))
+}
+
+func TestWrite(t *testing.T) {
+	r := &Report{
+		DryRun: true,
+		Projects: []*Project{{
+			Name:       "myproject",
+			Containers: []Container{{ID: "abc123", Name: "app", State: "running"}},
+			Resources:  []Resource{},
+		}},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatJSON, r))
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, true, got["dryRun"])
+		project := got["projects"].([]any)[0].(map[string]any)
+		assert.Equal(t, "myproject", project["name"])
+		assert.NotContains(t, project, "error")
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatYAML, r))
+
+		var got Report
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, r, &got)
+	})
+
+	t.Run("human is not structured", func(t *testing.T) {
+		assert.Error(t, Write(&bytes.Buffer{}, FormatHuman, r))
+	})
+}
+
+func TestResults(t *testing.T) {
+	resources := Results([]docker.ActionResult{
+		{Type: docker.ResourceContainer, ID: "abc123", Name: "app", Action: docker.ActionStop},
+		{Type: docker.ResourceContainer, ID: "abc123", Name: "app", Action: docker.ActionRemove, Err: errors.New("in use")},
+	})
+
+	require.Len(t, resources, 2)
+	assert.Equal(t, StatusOK, resources[0].Status)
+	assert.Empty(t, resources[0].Error)
+	assert.Equal(t, StatusFailed, resources[1].Status)
+	assert.Equal(t, "in use", resources[1].Error)
+}
+
+func TestPlanned(t *testing.T) {
+	plan := &docker.Plan{
+		ProjectName:      "myproject",
+		Containers:       []docker.ContainerInfo{{ID: "abc123", Names: []string{"/app"}}},
+		RemoveContainers: true,
+		Networks:         []docker.NetworkInfo{{ID: "net1", Name: "myproject_default"}},
+		Volumes:          []docker.VolumeInfo{{Name: "myproject_data"}},
+	}
+
+	resources := Planned(plan)
+
+	assert.Equal(t, []Resource{
+		{Type: "container", ID: "abc123", Name: "app", Action: "stop", Status: StatusPlanned},
+		{Type: "container", ID: "abc123", Name: "app", Action: "remove", Status: StatusPlanned},
+		{Type: "network", ID: "net1", Name: "myproject_default", Action: "remove", Status: StatusPlanned},
+		{Type: "volume", ID: "myproject_data", Name: "myproject_data", Action: "remove", Status: StatusPlanned},
+	}, resources)
+}
+
+func TestPlannedBackup(t *testing.T) {
+	plan := &docker.Plan{
+		Volumes:   []docker.VolumeInfo{{Name: "myproject_data"}},
+		BackupDir: "/backups",
+	}
+
+	assert.Equal(t, []Resource{
+		{Type: "volume", ID: "myproject_data", Name: "myproject_data", Action: "backup", Status: StatusPlanned},
+		{Type: "volume", ID: "myproject_data", Name: "myproject_data", Action: "remove", Status: StatusPlanned},
+	}, Planned(plan))
+}
+
+func TestPlannedEmpty(t *testing.T) {
+	resources := Planned(&docker.Plan{})
+	assert.NotNil(t, resources)
+	assert.Empty(t, resources)
+}
+
+func TestTally(t *testing.T) {
+	r := &Report{
+		Projects: []*Project{
+			{
+				Name: "web",
+				Resources: []Resource{
+					{Status: StatusOK},
+					{Status: StatusOK},
+					{Status: StatusFailed},
+				},
+				Error:     "failed to stop container web123: timeout",
+				Reclaimed: 1000,
+			},
+			{
+				Name:      "api",
+				Resources: []Resource{},
+				Error:     "failed to list networks",
+			},
+		},
+	}
+
+	s := r.Tally()
+
+	assert.Equal(t, Summary{OK: 2, Failed: 1, FailedProjects: 2, Reclaimed: 1000}, s)
+	assert.True(t, s.PartialFailure())
+
+	t.Run("reclaimed space is unknown if it is for any project", func(t *testing.T) {
+		r.Projects = append(r.Projects, &Project{Name: "db", Reclaimed: -1})
+		assert.Equal(t, int64(-1), r.Tally().Reclaimed)
+	})
+}
+
+func TestPartialFailure(t *testing.T) {
+	assert.False(t, Summary{OK: 3}.PartialFailure())
+	assert.False(t, Summary{Failed: 2, FailedProjects: 1}.PartialFailure())
+	assert.False(t, Summary{FailedProjects: 1}.PartialFailure())
+	assert.True(t, Summary{OK: 1, Failed: 1, FailedProjects: 1}.PartialFailure())
+	assert.True(t, Summary{OK: 1, FailedProjects: 1}.PartialFailure())
+}
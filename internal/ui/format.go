@@ -0,0 +1,24 @@
+package ui
+
+import "fmt"
+
+// FormatSize formats a size in bytes for display, e.g. "1.5 GB".
+// Negative sizes are reported as unknown.
+func FormatSize(size int64) string {
+	if size < 0 {
+		return "size unknown"
+	}
+
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,16 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "size unknown", FormatSize(-1))
+	assert.Equal(t, "0 B", FormatSize(0))
+	assert.Equal(t, "999 B", FormatSize(999))
+	assert.Equal(t, "1.5 kB", FormatSize(1500))
+	assert.Equal(t, "2.0 MB", FormatSize(2_000_000))
+	assert.Equal(t, "12.3 GB", FormatSize(12_345_678_901))
+}
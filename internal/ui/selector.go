@@ -2,14 +2,21 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"slices"
 
 	"github.com/dev-shimada/dcstop/internal/devcontainer"
 	"github.com/dev-shimada/dcstop/internal/docker"
 	"github.com/manifoldco/promptui"
 )
 
-// deduplicateConfigs removes configs with duplicate project names,
-// keeping only the first occurrence of each unique project name.
+// deduplicateConfigs removes configs that stand for the same devcontainer,
+// keeping only the first occurrence of each. Compose-based configs are the same
+// if they resolve to the same compose project; image and Dockerfile based configs
+// are told apart by their path, as their derived names are not unique across
+// workspaces (e.g. two repositories with .devcontainer/python).
+// COMPOSE_PROJECT_NAME in the environment applies to every compose file, so it
+// collapses all compose-based configs, e.g. from --recursive, into the first one.
 func deduplicateConfigs(configs []*devcontainer.Config) []*devcontainer.Config {
 	if len(configs) <= 1 {
 		return configs
@@ -19,9 +26,12 @@ func deduplicateConfigs(configs []*devcontainer.Config) []*devcontainer.Config {
 	result := make([]*devcontainer.Config, 0, len(configs))
 
 	for _, cfg := range configs {
-		projectName := docker.DeriveProjectNameFromConfig(cfg)
-		if !seen[projectName] {
-			seen[projectName] = true
+		key := "config:" + cfg.ConfigPath
+		if cfg.IsComposeBased() {
+			key = "compose:" + docker.DeriveProjectNameFromConfig(cfg)
+		}
+		if !seen[key] {
+			seen[key] = true
 			result = append(result, cfg)
 		}
 	}
@@ -29,36 +39,118 @@ func deduplicateConfigs(configs []*devcontainer.Config) []*devcontainer.Config {
 	return result
 }
 
+// ContainerCounts are the containers of a devcontainer config, by state.
+type ContainerCounts struct {
+	Running int
+	Stopped int
+}
+
+// Total returns the number of containers.
+func (c ContainerCounts) Total() int {
+	return c.Running + c.Stopped
+}
+
+// CountFunc counts the containers of a devcontainer config, for display in a selection.
+type CountFunc func(cfg *devcontainer.Config) (ContainerCounts, error)
+
+// configItem is an entry of a selection list. Its exported fields are used by
+// the templates of configTemplates.
+type configItem struct {
+	Label string
+	// State summarizes the container counts, if they were requested.
+	State string
+	// Idle is set if the config has no running containers, which dims the entry.
+	Idle         bool
+	ConfigPath   string
+	ComposeFiles []string
+
+	cfg *devcontainer.Config
+}
+
+// configLabel describes a config in a selection list, by project name, kind and
+// workspace folder, which tells apart configs found in different workspaces.
+func configLabel(cfg *devcontainer.Config) string {
+	return fmt.Sprintf("%s (%s) - %s", docker.DeriveProjectNameFromConfig(cfg), cfg.Kind(), cfg.WorkspaceFolder())
+}
+
+// configItems builds the selection entries for configs, counting their containers
+// with count unless it is nil. Configs with nothing running are listed last.
+// Configs whose containers cannot be counted are not dimmed, as they may be running.
+func configItems(configs []*devcontainer.Config, count CountFunc) []configItem {
+	items := make([]configItem, len(configs))
+	for i, cfg := range configs {
+		items[i] = configItem{
+			Label:      configLabel(cfg),
+			ConfigPath: cfg.ConfigPath,
+			cfg:        cfg,
+		}
+		if cfg.IsComposeBased() {
+			items[i].ComposeFiles = cfg.GetComposeFiles()
+		}
+		if count == nil {
+			continue
+		}
+
+		counts, err := count(cfg)
+		if err != nil {
+			items[i].State = "state unknown"
+			continue
+		}
+		items[i].State = fmt.Sprintf("%d running, %d stopped, %d total", counts.Running, counts.Stopped, counts.Total())
+		items[i].Idle = counts.Running == 0
+	}
+
+	slices.SortStableFunc(items, func(a, b configItem) int {
+		switch {
+		case a.Idle == b.Idle:
+			return 0
+		case b.Idle:
+			return -1
+		default:
+			return 1
+		}
+	})
+	return items
+}
+
+// configTemplates renders configItems with their container counts, dimming configs
+// with nothing running, and shows the files of the highlighted config below the list.
+func configTemplates() *promptui.SelectTemplates {
+	return &promptui.SelectTemplates{
+		Active:   `{{ "▸" | bold }} {{ .Label | underline }}{{ if .State }}  [{{ .State }}]{{ end }}`,
+		Inactive: `  {{ if .Idle }}{{ .Label | faint }}  {{ printf "[%s]" .State | faint }}{{ else }}{{ .Label }}{{ if .State }}  [{{ .State }}]{{ end }}{{ end }}`,
+		Selected: `{{ "✔" | green }} {{ .Label | faint }}`,
+		Details: `{{ if .ConfigPath }}
+{{ "Config:" | faint }}   {{ .ConfigPath }}
+{{- range .ComposeFiles }}
+{{ "Compose:" | faint }}  {{ . }}
+{{- end }}{{ end }}`,
+	}
+}
+
 // SelectConfig prompts the user to select a devcontainer config when multiple are found.
-func SelectConfig(configs []*devcontainer.Config) (*devcontainer.Config, error) {
+// Unless count is nil, each config is shown with its containers by state.
+func SelectConfig(configs []*devcontainer.Config, count CountFunc) (*devcontainer.Config, error) {
 	if len(configs) == 0 {
 		return nil, fmt.Errorf("no configs to select from")
 	}
 
-	// Deduplicate configs by project name
+	// Deduplicate configs that share a compose project
 	uniqueConfigs := deduplicateConfigs(configs)
 
 	if len(uniqueConfigs) == 1 {
 		return uniqueConfigs[0], nil
 	}
 
-	// Build display items
-	items := make([]string, len(uniqueConfigs))
-	for i, cfg := range uniqueConfigs {
-		configType := "image"
-		if cfg.IsComposeBased() {
-			configType = "compose"
-		}
-
-		projectName := docker.DeriveProjectNameFromConfig(cfg)
-
-		items[i] = fmt.Sprintf("%s (%s)", projectName, configType)
-	}
+	items := configItems(uniqueConfigs, count)
 
 	prompt := promptui.Select{
-		Label: "Select devcontainer",
-		Items: items,
-		Size:  10,
+		Label:     "Select devcontainer",
+		Items:     items,
+		Templates: configTemplates(),
+		Size:      10,
+		// Keep stdout free for structured output
+		Stdout: os.Stderr,
 	}
 
 	idx, _, err := prompt.Run()
@@ -66,7 +158,112 @@ func SelectConfig(configs []*devcontainer.Config) (*devcontainer.Config, error)
 		return nil, fmt.Errorf("selection cancelled: %w", err)
 	}
 
-	return uniqueConfigs[idx], nil
+	return items[idx].cfg, nil
+}
+
+// Items that do not stand for a config in a multi-selection.
+const (
+	allItem  = "All"
+	doneItem = "Done"
+)
+
+// checkbox returns the check mark of an item in a multi-selection.
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// multiSelectItems builds the entries of a checkbox-style selection: the entry that
+// selects or deselects every config, each config with its check mark, and the entry
+// that ends the selection.
+func multiSelectItems(configs []configItem, selected []bool) []configItem {
+	count := 0
+	for _, s := range selected {
+		if s {
+			count++
+		}
+	}
+
+	items := make([]configItem, 0, len(configs)+2)
+	items = append(items, configItem{Label: fmt.Sprintf("%s %s", checkbox(count == len(configs)), allItem)})
+	for i, item := range configs {
+		item.Label = fmt.Sprintf("%s %s", checkbox(selected[i]), item.Label)
+		items = append(items, item)
+	}
+	return append(items, configItem{Label: fmt.Sprintf("%s (%d selected)", doneItem, count)})
+}
+
+// toggleSelection applies choosing item idx of multiSelectItems to selected,
+// and returns true if the item ends the selection.
+func toggleSelection(selected []bool, idx int) bool {
+	switch {
+	case idx == 0:
+		// Select every item, or deselect them all if they already are
+		all := !slices.Contains(selected, false)
+		for i := range selected {
+			selected[i] = !all
+		}
+	case idx <= len(selected):
+		selected[idx-1] = !selected[idx-1]
+	default:
+		return true
+	}
+	return false
+}
+
+// SelectConfigs prompts the user to select any number of devcontainer configs
+// when multiple are found. Choosing an entry toggles it, and All toggles every
+// entry; choosing Done returns the selected configs in the order they are listed.
+// Unless count is nil, each config is shown with its containers by state.
+func SelectConfigs(configs []*devcontainer.Config, count CountFunc) ([]*devcontainer.Config, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no configs to select from")
+	}
+
+	// Deduplicate configs that share a compose project
+	uniqueConfigs := deduplicateConfigs(configs)
+
+	if len(uniqueConfigs) == 1 {
+		return uniqueConfigs, nil
+	}
+
+	items := configItems(uniqueConfigs, count)
+	selected := make([]bool, len(items))
+
+	// Start on the first config rather than All
+	cursor := 1
+	for {
+		prompt := promptui.Select{
+			Label:     "Select devcontainers (Enter to toggle)",
+			Items:     multiSelectItems(items, selected),
+			Templates: configTemplates(),
+			Size:      10,
+			// Toggling is not a final choice
+			HideSelected: true,
+			// Keep stdout free for structured output
+			Stdout: os.Stderr,
+		}
+
+		// Each toggle redraws the list, so keep the cursor where it was
+		idx, _, err := prompt.RunCursorAt(cursor, max(0, cursor-prompt.Size+1))
+		if err != nil {
+			return nil, fmt.Errorf("selection cancelled: %w", err)
+		}
+		if toggleSelection(selected, idx) {
+			break
+		}
+		cursor = idx
+	}
+
+	var result []*devcontainer.Config
+	for i, item := range items {
+		if selected[i] {
+			result = append(result, item.cfg)
+		}
+	}
+	return result, nil
 }
 
 // Confirm prompts the user for confirmation.
@@ -74,6 +271,7 @@ func Confirm(message string) (bool, error) {
 	prompt := promptui.Prompt{
 		Label:     message,
 		IsConfirm: true,
+		Stdout:    os.Stderr,
 	}
 
 	_, err := prompt.Run()
@@ -86,3 +284,12 @@ func Confirm(message string) (bool, error) {
 
 	return true, nil
 }
+
+// IsInteractive returns true if stdin is a terminal, i.e. the user can answer prompts.
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
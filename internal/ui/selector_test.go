@@ -1,11 +1,15 @@
 package ui
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/dev-shimada/dcstop/internal/devcontainer"
+	"github.com/manifoldco/promptui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -48,6 +52,7 @@ func TestDeduplicateConfigs(t *testing.T) {
 	})
 
 	t.Run("keeps configs with different project names", func(t *testing.T) {
+		t.Setenv("COMPOSE_PROJECT_NAME", "")
 		tmpDir := t.TempDir()
 
 		// Create two configs with different project names
@@ -81,6 +86,47 @@ func TestDeduplicateConfigs(t *testing.T) {
 		assert.Len(t, deduplicated, 2)
 	})
 
+	t.Run("keeps image configs with the same subfolder name in different workspaces", func(t *testing.T) {
+		root := t.TempDir()
+		for _, workspace := range []string{"a", "b"} {
+			dir := filepath.Join(root, workspace, ".devcontainer", "python")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "devcontainer.json"), []byte(`{"image": "python:3"}`), 0644))
+		}
+
+		candidates, err := devcontainer.FindDevcontainerConfigsRecursive(root, devcontainer.WalkOptions{MaxDepth: -1})
+		require.NoError(t, err)
+		var configs []*devcontainer.Config
+		for _, candidate := range candidates {
+			cfg, err := devcontainer.ParseConfig(candidate.Path)
+			require.NoError(t, err)
+			configs = append(configs, cfg)
+		}
+		require.Len(t, configs, 2)
+
+		// Both derive the project name "python", but are different devcontainers
+		deduplicated := deduplicateConfigs(configs)
+		assert.Equal(t, configs, deduplicated)
+	})
+
+	t.Run("keeps image configs of same-named workspaces under different parents", func(t *testing.T) {
+		root := t.TempDir()
+		var configs []*devcontainer.Config
+		for _, parent := range []string{"work", "personal"} {
+			dir := filepath.Join(root, parent, "app", ".devcontainer")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			configPath := filepath.Join(dir, "devcontainer.json")
+			require.NoError(t, os.WriteFile(configPath, []byte(`{"image": "golang:1.21"}`), 0644))
+
+			cfg, err := devcontainer.ParseConfig(configPath)
+			require.NoError(t, err)
+			configs = append(configs, cfg)
+		}
+
+		deduplicated := deduplicateConfigs(configs)
+		assert.Len(t, deduplicated, 2)
+	})
+
 	t.Run("handles empty list", func(t *testing.T) {
 		configs := []*devcontainer.Config{}
 		deduplicated := deduplicateConfigs(configs)
@@ -103,3 +149,168 @@ func TestDeduplicateConfigs(t *testing.T) {
 		assert.Len(t, deduplicated, 1)
 	})
 }
+
+// writeImageConfig writes an image-based devcontainer.json into a new workspace
+// named name and parses it.
+func writeImageConfig(t *testing.T, name string) *devcontainer.Config {
+	t.Helper()
+	devcontainerDir := filepath.Join(t.TempDir(), name, ".devcontainer")
+	require.NoError(t, os.MkdirAll(devcontainerDir, 0755))
+	configPath := filepath.Join(devcontainerDir, "devcontainer.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"image": "golang:1.21"}`), 0644))
+
+	cfg, err := devcontainer.ParseConfig(configPath)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestConfigItems(t *testing.T) {
+	api := writeImageConfig(t, "api")
+	web := writeImageConfig(t, "web")
+	db := writeImageConfig(t, "db")
+
+	counts := map[*devcontainer.Config]ContainerCounts{
+		api: {Running: 0, Stopped: 1},
+		web: {Running: 2, Stopped: 1},
+	}
+	count := func(cfg *devcontainer.Config) (ContainerCounts, error) {
+		c, ok := counts[cfg]
+		if !ok {
+			return ContainerCounts{}, errors.New("daemon unavailable")
+		}
+		return c, nil
+	}
+
+	t.Run("shows container counts and lists idle configs last", func(t *testing.T) {
+		items := configItems([]*devcontainer.Config{api, web, db}, count)
+
+		require.Len(t, items, 3)
+		assert.Same(t, web, items[0].cfg)
+		assert.Equal(t, "2 running, 1 stopped, 3 total", items[0].State)
+		assert.False(t, items[0].Idle)
+
+		// Configs that could not be counted may be running, so they are not dimmed
+		assert.Same(t, db, items[1].cfg)
+		assert.Equal(t, "state unknown", items[1].State)
+		assert.False(t, items[1].Idle)
+
+		assert.Same(t, api, items[2].cfg)
+		assert.Equal(t, "0 running, 1 stopped, 1 total", items[2].State)
+		assert.True(t, items[2].Idle)
+		assert.Equal(t, api.ConfigPath, items[2].ConfigPath)
+		assert.Contains(t, items[2].Label, "(image)")
+	})
+
+	t.Run("keeps the order without counts", func(t *testing.T) {
+		items := configItems([]*devcontainer.Config{api, web}, nil)
+
+		require.Len(t, items, 2)
+		assert.Same(t, api, items[0].cfg)
+		assert.Empty(t, items[0].State)
+		assert.False(t, items[0].Idle)
+		assert.Same(t, web, items[1].cfg)
+	})
+}
+
+func TestConfigTemplates(t *testing.T) {
+	tpls := configTemplates()
+	render := func(text string, item configItem) string {
+		t.Helper()
+		tpl, err := template.New("").Funcs(promptui.FuncMap).Parse(text)
+		require.NoError(t, err)
+		var sb strings.Builder
+		require.NoError(t, tpl.Execute(&sb, item))
+		return sb.String()
+	}
+
+	item := configItem{
+		Label:        "app (compose) - /src/app",
+		State:        "1 running, 0 stopped, 1 total",
+		ConfigPath:   "/src/app/.devcontainer/devcontainer.json",
+		ComposeFiles: []string{"/src/app/compose.yml", "/src/app/compose.dev.yml"},
+	}
+
+	t.Run("details show the config path and compose files", func(t *testing.T) {
+		details := render(tpls.Details, item)
+
+		assert.Contains(t, details, item.ConfigPath)
+		assert.Contains(t, details, "/src/app/compose.yml")
+		assert.Contains(t, details, "/src/app/compose.dev.yml")
+	})
+
+	t.Run("no details for items without a config", func(t *testing.T) {
+		assert.Empty(t, strings.TrimSpace(render(tpls.Details, configItem{Label: "Done (0 selected)"})))
+	})
+
+	t.Run("entries show the container counts", func(t *testing.T) {
+		assert.Contains(t, render(tpls.Active, item), item.State)
+		assert.Contains(t, render(tpls.Inactive, item), item.State)
+
+		item.Idle = true
+		assert.Contains(t, render(tpls.Inactive, item), item.State)
+	})
+}
+
+func TestMultiSelectItems(t *testing.T) {
+	labels := func(items []configItem) []string {
+		result := make([]string, len(items))
+		for i, item := range items {
+			result[i] = item.Label
+		}
+		return result
+	}
+	configs := []configItem{{Label: "api (image)"}, {Label: "web (compose)"}, {Label: "db (compose)"}}
+
+	t.Run("marks selected items and counts them", func(t *testing.T) {
+		items := multiSelectItems(configs, []bool{true, false, true})
+
+		assert.Equal(t, []string{
+			"[ ] All",
+			"[x] api (image)",
+			"[ ] web (compose)",
+			"[x] db (compose)",
+			"Done (2 selected)",
+		}, labels(items))
+		// The labels of the configs themselves are left alone
+		assert.Equal(t, "api (image)", configs[0].Label)
+	})
+
+	t.Run("marks All when every item is selected", func(t *testing.T) {
+		items := multiSelectItems(configs[:2], []bool{true, true})
+
+		assert.Equal(t, []string{"[x] All", "[x] api (image)", "[x] web (compose)", "Done (2 selected)"}, labels(items))
+	})
+}
+
+func TestToggleSelection(t *testing.T) {
+	t.Run("toggles a single item", func(t *testing.T) {
+		selected := []bool{false, false, false}
+
+		assert.False(t, toggleSelection(selected, 2))
+		assert.Equal(t, []bool{false, true, false}, selected)
+
+		assert.False(t, toggleSelection(selected, 2))
+		assert.Equal(t, []bool{false, false, false}, selected)
+	})
+
+	t.Run("All selects every item", func(t *testing.T) {
+		selected := []bool{true, false, true}
+
+		assert.False(t, toggleSelection(selected, 0))
+		assert.Equal(t, []bool{true, true, true}, selected)
+	})
+
+	t.Run("All deselects every item when all are selected", func(t *testing.T) {
+		selected := []bool{true, true}
+
+		assert.False(t, toggleSelection(selected, 0))
+		assert.Equal(t, []bool{false, false}, selected)
+	})
+
+	t.Run("Done ends the selection", func(t *testing.T) {
+		selected := []bool{true, false}
+
+		assert.True(t, toggleSelection(selected, 3))
+		assert.Equal(t, []bool{true, false}, selected)
+	})
+}